@@ -0,0 +1,44 @@
+package envs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ageArmorHeader starts armored age ciphertexts
+const ageArmorHeader = "-----BEGIN AGE ENCRYPTED FILE-----"
+
+// AgeCommand is the age binary WithAgeIdentity runs to decrypt values
+var AgeCommand = "age"
+
+// WithAgeIdentity decrypts armored age encrypted values with the identity file at path, so selected secrets
+// in otherwise plain .env files stay encrypted at rest. other values are left as they are
+func WithAgeIdentity(path string) Option {
+	return WithValueHook(func(ctx context.Context, key, value string) (string, error) {
+		armored := strings.TrimSpace(value)
+		if !strings.HasPrefix(armored, ageArmorHeader) {
+			return value, nil
+		}
+
+		var stderr bytes.Buffer
+		// #nosec G204 -- the identity path is passed as a single argument to the configured age binary
+		cmd := exec.CommandContext(ctx, AgeCommand, "--decrypt", "--identity", filepath.Clean(path))
+		cmd.Stdin = strings.NewReader(armored + "\n")
+		cmd.Stderr = &stderr
+
+		out, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", fmt.Errorf("age: %w: %s", err, msg)
+			}
+
+			return "", fmt.Errorf("age: %w", err)
+		}
+
+		return string(out), nil
+	})
+}
@@ -0,0 +1,54 @@
+package envs_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestWithAgeIdentity(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake age binary is a shell script")
+	}
+
+	dir := t.TempDir()
+	identity := filepath.Join(dir, "key.txt")
+	age := filepath.Join(dir, "age")
+	script := "#!/bin/sh\n[ \"$1 $2 $3\" = \"--decrypt --identity " + identity + "\" ] ||\n" +
+		"{ echo \"no identity matched\" >&2; exit 1; }\ngrep -q YWdl && printf s3cret\n"
+	if err := os.WriteFile(age, []byte(script), 0o700); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	old := envs.AgeCommand
+	envs.AgeCommand = age
+	t.Cleanup(func() { envs.AgeCommand = old })
+
+	type Config struct {
+		Password string `env:"PASSWORD,secret"`
+		User     string `env:"USER"`
+	}
+
+	armored := "-----BEGIN AGE ENCRYPTED FILE-----\nYWdl\n-----END AGE ENCRYPTED FILE-----"
+	values := envs.FromMap(map[string]string{"DB_PASSWORD": armored, "DB_USER": "admin"})
+
+	var got Config
+	err := envs.NewParserOpts(envs.WithValueFunc(values), envs.WithAgeIdentity(identity)).ParseStruct(&got, "DB")
+	if err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if want := (Config{Password: "s3cret", User: "admin"}); got != want {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+
+	err = envs.NewParserOpts(envs.WithValueFunc(values), envs.WithAgeIdentity(filepath.Join(dir, "other.txt"))).
+		ParseStruct(&got, "DB")
+	if err == nil || !strings.Contains(err.Error(), "no identity matched") {
+		t.Errorf("ParseStruct() error = %v, want the age error", err)
+	}
+}
@@ -0,0 +1,89 @@
+package envs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// cachedSource is the Source returned by Cached
+type cachedSource struct {
+	source Source
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is a finished or running lookup, done is closed once it finished
+type cacheEntry struct {
+	done    chan struct{}
+	value   string
+	found   bool
+	err     error
+	expires time.Time
+}
+
+// Cached wraps source so the result of looking up a key is reused for ttl, or for good when ttl is zero.
+// concurrent lookups of the same key share a single call to source and failed lookups are not cached,
+// so parsing large structs against a remote store does not hammer it
+func Cached(source Source, ttl time.Duration) Source {
+	return &cachedSource{source: source, ttl: ttl, entries: map[string]*cacheEntry{}}
+}
+
+func (c *cachedSource) Lookup(ctx context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok && !c.expired(e) {
+		c.mu.Unlock()
+
+		select {
+		case <-e.done:
+			return e.value, e.found, e.err
+		case <-ctx.Done():
+			return "", false, ctx.Err()
+		}
+	}
+
+	e = &cacheEntry{done: make(chan struct{})}
+	c.entries[key] = e
+	c.mu.Unlock()
+
+	e.value, e.found, e.err = c.source.Lookup(ctx, key)
+	e.expires = time.Now().Add(c.ttl)
+
+	if e.err != nil {
+		c.mu.Lock()
+		if c.entries[key] == e {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+	}
+
+	close(e.done)
+	return e.value, e.found, e.err
+}
+
+// Wait reports changes of the wrapped source when it is a Waiter and drops the cached lookups after each one,
+// it waits for ctx to be done otherwise
+func (c *cachedSource) Wait(ctx context.Context) error {
+	if err := waitFor(ctx, c.source); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.entries = map[string]*cacheEntry{}
+	c.mu.Unlock()
+
+	return nil
+}
+
+// expired reports whether a finished entry has outlived the ttl, running lookups never expire
+func (c *cachedSource) expired(e *cacheEntry) bool {
+	select {
+	case <-e.done:
+		return c.ttl > 0 && time.Now().After(e.expires)
+	default:
+		return false
+	}
+}
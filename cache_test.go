@@ -0,0 +1,63 @@
+package envs_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func TestCached(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fail := atomic.Bool{}
+	source := envs.Cached(envs.SourceFunc(func(_ context.Context, key string) (string, bool, error) {
+		calls.Add(1)
+		<-release
+
+		if fail.Load() {
+			return "", false, errors.New("connection refused")
+		}
+
+		return "value of " + key, true, nil
+	}), 50*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if val, found, err := source.Lookup(context.Background(), "KEY"); val != "value of KEY" || !found || err != nil {
+				t.Errorf("Lookup() = %q, %v, %v", val, found, err)
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("source called %d times for concurrent lookups, want 1", got)
+	}
+
+	if _, _, err := source.Lookup(context.Background(), "KEY"); err != nil || calls.Load() != 1 {
+		t.Errorf("Lookup() = %v with %d calls, want the cached value", err, calls.Load())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	fail.Store(true)
+	if _, _, err := source.Lookup(context.Background(), "KEY"); err == nil || calls.Load() != 2 {
+		t.Errorf("Lookup() after the ttl = %v with %d calls, want a failed call", err, calls.Load())
+	}
+
+	fail.Store(false)
+	if _, _, err := source.Lookup(context.Background(), "KEY"); err != nil || calls.Load() != 3 {
+		t.Errorf("Lookup() after an error = %v with %d calls, want a new call", err, calls.Load())
+	}
+}
@@ -0,0 +1,25 @@
+package envs
+
+import (
+	"crypto/x509"
+	"errors"
+	r "reflect"
+)
+
+var certPoolType = r.TypeOf(&x509.CertPool{})
+
+// ParseCertPool builds a certificate pool from a PEM encoded CA bundle, values starting with file= are read
+// from the path after it. blocks other than certificates are skipped
+func ParseCertPool(str string) (*x509.CertPool, error) {
+	data, err := readPEM(str)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("no PEM encoded certificates found")
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,59 @@
+package envs_test
+
+import (
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestParseCertPool(t *testing.T) {
+	cert, key := selfSigned(t)
+	other, _ := selfSigned(t)
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, []byte(cert+other), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	type Config struct {
+		CA     *x509.CertPool `env:"CA"`
+		Bundle *x509.CertPool `env:"BUNDLE"`
+		Unset  *x509.CertPool `env:"UNSET"`
+	}
+
+	values := envs.FromMap(map[string]string{"POOL_CA": key + cert, "POOL_BUNDLE": "file=" + path})
+
+	var cfg Config
+	if err := envs.NewParserOpts(envs.WithValueFunc(values)).ParseStruct(&cfg, "POOL"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	want := x509.NewCertPool()
+	want.AppendCertsFromPEM([]byte(cert))
+	if !cfg.CA.Equal(want) {
+		t.Errorf("CA does not hold the certificate")
+	}
+
+	want.AppendCertsFromPEM([]byte(other))
+	if !cfg.Bundle.Equal(want) {
+		t.Errorf("Bundle does not hold both certificates")
+	}
+
+	if cfg.Unset != nil {
+		t.Errorf("Unset = %v, want nil", cfg.Unset)
+	}
+
+	t.Setenv("POOL_GET", cert)
+	if got := envs.Get[*x509.CertPool]("POOL_GET"); got == nil {
+		t.Errorf("Get() = nil, want a pool")
+	}
+
+	for _, value := range []string{"not a certificate", key, "file=" + filepath.Join(t.TempDir(), "missing.pem")} {
+		if _, err := envs.ParseCertPool(value); err == nil {
+			t.Errorf("ParseCertPool(%q) expected an error", value)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OZahed/envs"
+)
+
+// checkCommand validates the process environment or the given .env files against a schema generated by envs.Schema,
+// it exits with 1 when a variable is missing, invalid or unknown.
+func checkCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: envs check -schema file [-f file]... [-prefix PREFIX]")
+		flags.PrintDefaults()
+	}
+
+	var envFiles files
+	flags.Var(&envFiles, "f", "`.env file` to check instead of the process environment, can be repeated")
+	schemaFile := flags.String("schema", "", "JSON Schema `file` generated by envs.Schema")
+	prefix := flags.String("prefix", "", "report variables starting with `PREFIX`_ that the schema does not describe")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *schemaFile == "" || flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	schema, err := os.ReadFile(filepath.Clean(*schemaFile))
+	if err != nil {
+		fmt.Fprintf(stderr, "envs check: %v\n", err)
+		return 1
+	}
+
+	values, err := loadValues(envFiles)
+	if err != nil {
+		fmt.Fprintf(stderr, "envs check: %v\n", err)
+		return 1
+	}
+
+	violations, err := envs.ValidateSchema(schema, values, *prefix)
+	if err != nil {
+		fmt.Fprintf(stderr, "envs check: %v\n", err)
+		return 1
+	}
+
+	failed := false
+	for _, v := range violations {
+		// without a prefix every variable of the process would be unknown
+		if v.Kind == envs.ViolationUnknown && len(envFiles) == 0 && *prefix == "" {
+			continue
+		}
+
+		failed = true
+		fmt.Fprintln(stdout, v)
+	}
+
+	if failed {
+		return 1
+	}
+
+	return 0
+}
+
+// loadValues reads the given .env files or the process environment when there are none
+func loadValues(envFiles []string) (map[string]string, error) {
+	if len(envFiles) != 0 {
+		return envs.LoadDotEnv(envFiles...)
+	}
+
+	environ := os.Environ()
+	values := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		// windows lists the directories of its drives as =C:=C:\dir, they are not configuration
+		if k, v := cutEnv(kv); !strings.HasPrefix(k, "=") {
+			values[k] = v
+		}
+	}
+
+	return values, nil
+}
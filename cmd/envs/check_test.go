@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func writeSchema(t *testing.T) string {
+	t.Helper()
+
+	type Config struct {
+		Port    int           `env:"PORT,default=8080"`
+		Token   string        `env:"TOKEN,required"`
+		Timeout time.Duration `env:"TIMEOUT"`
+	}
+
+	schema, err := envs.NewParserOpts(envs.WithPrefix("CHECK")).Schema(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err = os.WriteFile(path, schema, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestCheck(t *testing.T) {
+	schema := writeSchema(t)
+	valid := writeEnvFile(t, "CHECK_TOKEN=tok\nCHECK_PORT=80\n")
+	invalid := writeEnvFile(t, "CHECK_PORT=http\nCHECK_TIMEOUT=5s\nCHECK_EXTRA=1\n")
+
+	t.Setenv("CHECK_TOKEN", "tok")
+	t.Setenv("CHECK_TIMEOUT", "soon")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+		code int
+	}{
+		{name: "valid file", args: []string{"-schema", schema, "-f", valid}},
+		{
+			name: "invalid file",
+			args: []string{"-schema", schema, "-f", invalid},
+			want: "CHECK_EXTRA: unknown\n" +
+				"CHECK_PORT: invalid: \"http\" does not match ^[-+]?[0-9]+$\n" +
+				"CHECK_TOKEN: missing\n",
+			code: 1,
+		},
+		{
+			name: "environment",
+			args: []string{"-schema", schema},
+			want: "CHECK_TIMEOUT: invalid: \"soon\" does not match ^[-+]?(0|([0-9]+(\\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$\n",
+			code: 1,
+		},
+		{name: "missing schema", args: []string{"-f", valid}, code: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(append([]string{"check"}, tt.args...), &stdout, &stderr)
+			if code != tt.code {
+				t.Fatalf("exit code = %d, want %d, stderr: %s", code, tt.code, stderr.String())
+			}
+
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("stdout = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/OZahed/envs"
+)
+
+// diffCommand compares a .env file with the process environment, or two .env files with each other,
+// it exits with 1 when they differ.
+func diffCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: envs diff [-prefix PREFIX] [-values] file [other-file]")
+		flags.PrintDefaults()
+	}
+
+	prefix := flags.String("prefix", "", "only compare variables starting with `PREFIX`_")
+	showValues := flags.Bool("values", false, "print the values, they are hidden by default since they may be secrets")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		flags.Usage()
+		return 2
+	}
+
+	left, err := envs.LoadDotEnv(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "envs diff: %v\n", err)
+		return 1
+	}
+
+	var rightFiles []string
+	if flags.NArg() == 2 {
+		rightFiles = []string{flags.Arg(1)}
+	}
+
+	right, err := loadValues(rightFiles)
+	if err != nil {
+		fmt.Fprintf(stderr, "envs diff: %v\n", err)
+		return 1
+	}
+
+	lines := diffValues(filterPrefix(left, *prefix), filterPrefix(right, *prefix), *showValues)
+	for _, line := range lines {
+		fmt.Fprintln(stdout, line)
+	}
+
+	if len(lines) != 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// filterPrefix returns the values with keys starting with prefix followed by `_`, all of them when prefix is empty
+func filterPrefix(values map[string]string, prefix string) map[string]string {
+	if prefix == "" {
+		return values
+	}
+
+	filtered := make(map[string]string, len(values))
+	for k, v := range values {
+		if strings.HasPrefix(k, prefix+"_") {
+			filtered[k] = v
+		}
+	}
+
+	return filtered
+}
+
+// diffValues describes the keys added (+), removed (-) and changed (~) between left and right, sorted by key
+func diffValues(left, right map[string]string, showValues bool) []string {
+	keys := make([]string, 0, len(left)+len(right))
+	for k := range left {
+		keys = append(keys, k)
+	}
+
+	for k := range right {
+		if _, ok := left[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	var lines []string
+	for _, k := range keys {
+		l, inLeft := left[k]
+		r, inRight := right[k]
+
+		switch {
+		case !inLeft:
+			lines = append(lines, "+ "+diffEntry(k, r, showValues))
+		case !inRight:
+			lines = append(lines, "- "+diffEntry(k, l, showValues))
+		case l != r && showValues:
+			lines = append(lines, fmt.Sprintf("~ %s=%s -> %s", k, l, r))
+		case l != r:
+			lines = append(lines, "~ "+k)
+		}
+	}
+
+	return lines
+}
+
+func diffEntry(key, val string, showValues bool) string {
+	if !showValues {
+		return key
+	}
+
+	return key + "=" + val
+}
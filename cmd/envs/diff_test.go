@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	left := writeEnvFile(t, "DIFF_A=1\nDIFF_B=2\nDIFF_C=3\nOTHER=1\n")
+	right := writeEnvFile(t, "DIFF_A=1\nDIFF_B=20\nDIFF_D=4\nOTHER=2\n")
+
+	t.Setenv("DIFF_A", "1")
+	t.Setenv("DIFF_B", "2")
+	t.Setenv("DIFF_C", "3")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+		code int
+	}{
+		{
+			name: "two files",
+			args: []string{"-prefix", "DIFF", left, right},
+			want: "~ DIFF_B\n- DIFF_C\n+ DIFF_D\n",
+			code: 1,
+		},
+		{
+			name: "values",
+			args: []string{"-prefix", "DIFF", "-values", left, right},
+			want: "~ DIFF_B=2 -> 20\n- DIFF_C=3\n+ DIFF_D=4\n",
+			code: 1,
+		},
+		{
+			name: "environment",
+			args: []string{"-prefix", "DIFF", left},
+		},
+		{
+			name: "without prefix",
+			args: []string{left, right},
+			want: "~ DIFF_B\n- DIFF_C\n+ DIFF_D\n~ OTHER\n",
+			code: 1,
+		},
+		{name: "missing file", args: []string{}, code: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(append([]string{"diff"}, tt.args...), &stdout, &stderr)
+			if code != tt.code {
+				t.Fatalf("exit code = %d, want %d, stderr: %s", code, tt.code, stderr.String())
+			}
+
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("stdout = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/OZahed/envs"
+)
+
+// execCommand runs the command after -- with the process environment merged with the given .env files,
+// variables already set in the environment win unless -override is given.
+func execCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("exec", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: envs exec [-f file]... [-override] -- command [args...]")
+		flags.PrintDefaults()
+	}
+
+	var envFiles files
+	flags.Var(&envFiles, "f", "`.env file` to load, can be repeated, later files override earlier ones (default .env)")
+	override := flags.Bool("override", false, "let values from the files override the process environment")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	if len(envFiles) == 0 {
+		envFiles = files{".env"}
+	}
+
+	values, err := envs.LoadDotEnv(envFiles...)
+	if err != nil {
+		fmt.Fprintf(stderr, "envs exec: %v\n", err)
+		return 1
+	}
+
+	name, cmdArgs := flags.Arg(0), flags.Args()[1:]
+
+	// #nosec G204 -- running the given command is the point of exec
+	cmd := exec.Command(name, cmdArgs...)
+	cmd.Env = mergeEnv(os.Environ(), values, *override)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(stderr, "envs exec: %v\n", err)
+		return 127
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	go func() {
+		for sig := range signals {
+			_ = cmd.Process.Signal(sig)
+		}
+	}()
+
+	err = cmd.Wait()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "envs exec: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// mergeEnv adds values to environ, a KEY=VALUE list, existing keys are only replaced when override is true
+func mergeEnv(environ []string, values map[string]string, override bool) []string {
+	merged := make(map[string]string, len(environ)+len(values))
+	for _, kv := range environ {
+		k, v := cutEnv(kv)
+		merged[k] = v
+	}
+
+	for k, v := range values {
+		if _, ok := merged[k]; ok && !override {
+			continue
+		}
+
+		merged[k] = v
+	}
+
+	env := make([]string, 0, len(merged))
+	for k, v := range merged {
+		env = append(env, k+"="+v)
+	}
+
+	sort.Strings(env)
+	return env
+}
+
+// cutEnv splits a KEY=VALUE pair of an environment list on the first = after the name,
+// so windows entries like =C:=C:\dir keep =C: as their name
+func cutEnv(kv string) (key, value string) {
+	if i := strings.IndexByte(kv, '='); i > 0 {
+		return kv[:i], kv[i+1:]
+	}
+
+	if i := strings.IndexByte(kv[min(len(kv), 1):], '='); i >= 0 {
+		return kv[:i+1], kv[i+2:]
+	}
+
+	return kv, ""
+}
@@ -0,0 +1,63 @@
+// Command envs works with environment configurations from the command line.
+//
+// Usage:
+//
+//	envs exec [-f file]... [-override] -- command [args...]
+//	envs check -schema file [-f file]... [-prefix PREFIX]
+//	envs diff [-prefix PREFIX] [-values] file [other-file]
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// command is a subcommand of envs, it returns the process exit code
+type command func(args []string, stdout, stderr io.Writer) int
+
+var commands = map[string]command{
+	"exec":  execCommand,
+	"check": checkCommand,
+	"diff":  diffCommand,
+}
+
+const usage = `usage: envs <command> [flags]
+
+commands:
+  exec    run a command with the environment loaded from .env files
+  check   validate the environment or .env files against a JSON Schema
+  diff    compare a .env file with the environment or another .env file
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "envs: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	return cmd(args[1:], stdout, stderr)
+}
+
+// files is a repeatable -f flag
+type files []string
+
+func (f *files) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *files) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
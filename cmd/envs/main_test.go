@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestHelperProcess is the child process started by the exec tests, it prints the requested variables
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("ENVS_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+
+	code := 0
+	for _, arg := range args[1:] {
+		if c, ok := strings.CutPrefix(arg, "exit="); ok {
+			_, _ = fmt.Sscan(c, &code)
+			continue
+		}
+
+		fmt.Printf("%s=%s\n", arg, os.Getenv(arg))
+	}
+
+	os.Exit(code)
+}
+
+func writeEnvFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func helperArgs(args ...string) []string {
+	return append([]string{"--", os.Args[0], "-test.run=TestHelperProcess", "--"}, args...)
+}
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(nil, &stdout, &stderr); code != 2 {
+		t.Errorf("run() = %d, want 2", code)
+	}
+
+	if code := run([]string{"unknown"}, &stdout, &stderr); code != 2 {
+		t.Errorf("run(unknown) = %d, want 2", code)
+	}
+}
+
+func TestExec(t *testing.T) {
+	t.Setenv("ENVS_HELPER_PROCESS", "1")
+	t.Setenv("EXEC_KEEP", "process")
+
+	base := writeEnvFile(t, "EXEC_A=1\nEXEC_B=2\nEXEC_KEEP=file\n")
+	local := writeEnvFile(t, "EXEC_B=3\n")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+		code int
+	}{
+		{
+			name: "merged files",
+			args: append([]string{"-f", base, "-f", local}, helperArgs("EXEC_A", "EXEC_B", "EXEC_KEEP")...),
+			want: "EXEC_A=1\nEXEC_B=3\nEXEC_KEEP=process\n",
+		},
+		{
+			name: "override",
+			args: append([]string{"-f", base, "-override"}, helperArgs("EXEC_KEEP")...),
+			want: "EXEC_KEEP=file\n",
+		},
+		{
+			name: "exit code",
+			args: append([]string{"-f", base}, helperArgs("exit=3")...),
+			code: 3,
+		},
+		{
+			name: "missing file",
+			args: append([]string{"-f", filepath.Join(t.TempDir(), "missing")}, helperArgs()...),
+			code: 1,
+		},
+		{
+			name: "missing command",
+			args: []string{"-f", base},
+			code: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(append([]string{"exec"}, tt.args...), &stdout, &stderr)
+			if code != tt.code {
+				t.Fatalf("exit code = %d, want %d, stderr: %s", code, tt.code, stderr.String())
+			}
+
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("stdout = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	environ := []string{"A=1", "B=2"}
+	values := map[string]string{"B": "3", "C": "4"}
+
+	if got, want := mergeEnv(environ, values, false), []string{"A=1", "B=2", "C=4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeEnv() = %v, want %v", got, want)
+	}
+
+	if got, want := mergeEnv(environ, values, true), []string{"A=1", "B=3", "C=4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeEnv(override) = %v, want %v", got, want)
+	}
+
+	// windows keeps the directory of every drive in a variable named like =C:
+	drives := []string{`=C:=C:\app`, `=D:=D:\data`, "A=1"}
+	if got := mergeEnv(drives, nil, false); !reflect.DeepEqual(got, drives) {
+		t.Errorf("mergeEnv() = %v, want the drive directories kept as %v", got, drives)
+	}
+}
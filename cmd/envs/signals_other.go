@@ -0,0 +1,7 @@
+//go:build windows || js || wasip1 || plan9
+
+package main
+
+import "os"
+
+var forwardedSignals = []os.Signal{os.Interrupt}
@@ -0,0 +1,10 @@
+//go:build !windows && !js && !wasip1 && !plan9
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
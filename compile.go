@@ -0,0 +1,61 @@
+package envs
+
+import (
+	"context"
+	r "reflect"
+)
+
+// Compiled parses environment variables into values of type T, the fields and keys of T and its nested structs
+// are read ahead of time by Compile so parsing again on reload skips the type lookups.
+// a Compiled is safe for concurrent use.
+type Compiled[T any] struct {
+	parser *Parser
+	fields map[r.Type][]structField
+}
+
+// Compile builds a Parser from opts and reads the struct fields of T ahead of time
+func Compile[T any](opts ...Option) *Compiled[T] {
+	c := &Compiled[T]{parser: NewParserOpts(opts...), fields: map[r.Type][]structField{}}
+	c.compile(r.TypeOf((*T)(nil)).Elem())
+
+	return c
+}
+
+// compile stores the fields of t and of every struct type reachable from it
+func (c *Compiled[T]) compile(t r.Type) {
+	switch t.Kind() {
+	case r.Pointer, r.Slice, r.Array:
+		c.compile(t.Elem())
+	case r.Map:
+		c.compile(t.Key())
+		c.compile(t.Elem())
+	case r.Struct:
+		if _, ok := c.fields[t]; ok {
+			return
+		}
+
+		fields := c.parser.structFields(t)
+		c.fields[t] = fields
+		for _, f := range fields {
+			c.compile(f.typ)
+		}
+	}
+}
+
+// Parse returns a T filled the same way Parser.ParseStruct fills it, an empty prefix falls back to WithPrefix
+func (c *Compiled[T]) Parse(prefix string) (T, error) {
+	return c.ParseCtx(context.Background(), prefix)
+}
+
+// ParseCtx is Parse that stops once ctx is done, see Parser.ParseStructCtx
+func (c *Compiled[T]) ParseCtx(ctx context.Context, prefix string) (T, error) {
+	var dest T
+	err := c.parser.decode(&decodeState{fields: c.fields, ctx: ctx}, &dest, prefix)
+
+	return dest, err
+}
+
+// Resolutions returns where every field of the last Parse call got its value from
+func (c *Compiled[T]) Resolutions() []Resolution {
+	return c.parser.Resolutions()
+}
@@ -0,0 +1,84 @@
+package envs_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+type compiledConfig struct {
+	Name    string        `env:"NAME,default=svc"`
+	Timeout time.Duration `env:"TIMEOUT"`
+	Hosts   []string      `env:"HOSTS"`
+	Server  struct {
+		Port int `env:"PORT,required"`
+	} `env:"SERVER"`
+}
+
+func TestCompile(t *testing.T) {
+	values := map[string]string{"APP_TIMEOUT": "5s", "APP_HOSTS": "a,b", "APP_SERVER_PORT": "8080"}
+	c := envs.Compile[compiledConfig](envs.WithPrefix("APP"), envs.WithValueFunc(func(key, def string) string {
+		return values[key]
+	}))
+
+	want := compiledConfig{Name: "svc", Timeout: 5 * time.Second, Hosts: []string{"a", "b"}}
+	want.Server.Port = 8080
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			got, err := c.Parse("")
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Parse() = %+v, want %+v", got, want)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if res := c.Resolutions(); len(res) != 4 || res[3].Key != "APP_SERVER_PORT" {
+		t.Errorf("Resolutions() = %+v", res)
+	}
+
+	if _, err := c.Parse("OTHER"); err == nil {
+		t.Error("Parse(OTHER) expected an error for the required OTHER_SERVER_PORT")
+	}
+
+	if _, err := envs.Compile[int]().Parse(""); err == nil {
+		t.Error("Parse() expected an error for a non struct type")
+	}
+}
+
+func BenchmarkCompiled_Parse(b *testing.B) {
+	b.Setenv("BENCH_SERVER_PORT", "8080")
+	c := envs.Compile[compiledConfig]()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Parse("BENCH"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParser_ParseStruct(b *testing.B) {
+	b.Setenv("BENCH_SERVER_PORT", "8080")
+	p := envs.NewParser(nil, nil)
+
+	for i := 0; i < b.N; i++ {
+		var cfg compiledConfig
+		if err := p.ParseStruct(&cfg, "BENCH"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package envs
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConsulSource reads values from the Consul KV store, keys are looked up under Prefix
+// so a field with the key PORT is read from config/app/PORT with the prefix config/app/
+type ConsulSource struct {
+	// Address is the base URL of the Consul agent like http://127.0.0.1:8500
+	Address string
+	// Prefix is prepended to every key
+	Prefix string
+	// Token is sent as X-Consul-Token when it is not empty
+	Token string
+	// WaitTime is the longest Wait blocks for a single query, Consul uses 5 minutes when it is zero
+	WaitTime time.Duration
+	// Client sends the requests, http.DefaultClient is used when it is nil
+	Client *http.Client
+
+	mu    sync.Mutex
+	index string
+}
+
+// NewConsulSource creates a ConsulSource reading keys under prefix from the Consul agent at address
+func NewConsulSource(address, prefix string) *ConsulSource {
+	return &ConsulSource{Address: address, Prefix: prefix}
+}
+
+// Lookup reads the raw value of Prefix+key
+func (s *ConsulSource) Lookup(ctx context.Context, key string) (string, bool, error) {
+	u := s.url(s.Prefix+key, url.Values{"raw": {""}})
+	data, _, found, err := fetch(ctx, s.Client, http.MethodGet, u, s.header(), nil)
+	if err != nil || !found {
+		return "", false, err
+	}
+
+	return string(data), true, nil
+}
+
+// Wait blocks until a key under Prefix changes, WaitTime passes or ctx is done using Consul blocking queries,
+// the first call returns right away and records the current index. a reload loop can call it before ParseStruct
+func (s *ConsulSource) Wait(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := url.Values{"keys": {""}}
+	if s.index != "" {
+		query.Set("index", s.index)
+		if s.WaitTime > 0 {
+			query.Set("wait", strconv.FormatInt(s.WaitTime.Milliseconds(), 10)+"ms")
+		}
+	}
+
+	_, header, _, err := fetch(ctx, s.Client, http.MethodGet, s.url(s.Prefix, query), s.header(), nil)
+	if err != nil {
+		return err
+	}
+
+	s.index = header.Get("X-Consul-Index")
+	return nil
+}
+
+func (s *ConsulSource) url(key string, query url.Values) string {
+	return strings.TrimSuffix(s.Address, "/") + "/v1/kv/" + strings.TrimPrefix(key, "/") + "?" + query.Encode()
+}
+
+func (s *ConsulSource) header() http.Header {
+	if s.Token == "" {
+		return nil
+	}
+
+	return http.Header{"X-Consul-Token": {s.Token}}
+}
@@ -0,0 +1,161 @@
+package envs_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func TestConsulSource(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		index   = 1
+		changed = make(chan struct{})
+		store   = map[string]string{"config/app/APP_PORT": "9090"}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Consul-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		key := strings.TrimPrefix(req.URL.Path, "/v1/kv/")
+		query := req.URL.Query()
+		if query.Has("keys") {
+			if query.Get("index") != "" {
+				<-changed
+			}
+
+			mu.Lock()
+			w.Header().Set("X-Consul-Index", strconv.Itoa(index))
+			mu.Unlock()
+			return
+		}
+
+		mu.Lock()
+		val, ok := store[key]
+		mu.Unlock()
+		if !ok || !query.Has("raw") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(val))
+	}))
+	defer server.Close()
+
+	type Config struct {
+		Port int    `env:"PORT"`
+		Host string `env:"HOST,default=localhost"`
+	}
+
+	source := envs.NewConsulSource(server.URL, "config/app/")
+	source.Token = "token"
+	p := envs.NewParserOpts(envs.WithSource(source))
+
+	var got Config
+	if err := p.ParseStruct(&got, "APP"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if want := (Config{Port: 9090, Host: "localhost"}); got != want {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+
+	if err := source.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- source.Wait(context.Background()) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Wait() returned %v before a change", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	mu.Lock()
+	index++
+	store["config/app/APP_PORT"] = "7070"
+	mu.Unlock()
+	close(changed)
+
+	if err := <-done; err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	if err := p.ParseStruct(&got, "APP"); err != nil || got.Port != 7070 {
+		t.Errorf("ParseStruct() = %+v, %v, want port 7070", got, err)
+	}
+
+	source.Token = ""
+	if _, _, err := source.Lookup(context.Background(), "APP_PORT"); err == nil {
+		t.Error("Lookup() expected an error for a forbidden request")
+	}
+}
+
+func TestConsulSource_wrapped(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		port    = "9090"
+		changed = make(chan struct{})
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Has("keys") {
+			if req.URL.Query().Get("index") != "" {
+				<-changed
+			}
+
+			w.Header().Set("X-Consul-Index", "1")
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write([]byte(port))
+	}))
+	defer server.Close()
+
+	type Config struct {
+		Port int `env:"PORT"`
+	}
+
+	// the change reaches the Watcher through both wrappers and the cached port is dropped
+	source := envs.Cached(envs.WithRetry(envs.NewConsulSource(server.URL, "config/app/"), envs.RetryPolicy{}), time.Hour)
+	w, err := envs.NewWatcher[Config]("APP", 0, envs.WithSource(source))
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	changes := make(chan Config, 10)
+	w.OnChange(func(_, new Config) { changes <- new })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = w.Run(ctx) }()
+
+	mu.Lock()
+	port = "7070"
+	mu.Unlock()
+	close(changed)
+
+	select {
+	case got := <-changes:
+		if got.Port != 7070 {
+			t.Errorf("OnChange() got %+v, want port 7070", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change reported through Cached and WithRetry")
+	}
+}
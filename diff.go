@@ -0,0 +1,155 @@
+package envs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	r "reflect"
+)
+
+// Change is a field whose value differs between two configurations, values of fields tagged as `secret`
+// and of Redacted fields are masked
+type Change struct {
+	// Path is the dotted path of the field, like Server.Port
+	Path string
+	// Key is the key the field is read from
+	Key string
+	Old string
+	New string
+}
+
+// Diff returns the fields whose values differ between old and new in struct field order, like for logging what
+// changed after a reload. values that are not structs have no fields and no changes
+func Diff(old, new interface{}) []Change {
+	return NewParser(nil, nil).Diff(old, new)
+}
+
+// Diff returns the fields whose values differ between old and new using the parser prefix and key function
+func (m *Parser) Diff(old, new interface{}) []Change {
+	oldValues := map[string]string{}
+	_ = m.walk(r.ValueOf(old), m.prefix, "", func(f field) error {
+		oldValues[f.Path], _ = m.diffValue(f)
+		return nil
+	})
+
+	var changes []Change
+	_ = m.walk(r.ValueOf(new), m.prefix, "", func(f field) error {
+		val, secret := m.diffValue(f)
+		if val == oldValues[f.Path] {
+			return nil
+		}
+
+		change := Change{Path: f.Path, Key: f.Key, Old: oldValues[f.Path], New: val}
+		if secret {
+			change.Old, change.New = mask(change.Old), mask(change.New)
+		}
+
+		changes = append(changes, change)
+		return nil
+	})
+
+	return changes
+}
+
+// diffValue formats the value of f and reports whether it is a secret, Redacted values are revealed
+// so changes to them are found
+func (m *Parser) diffValue(f field) (string, bool) {
+	value, secret := f.Value, f.Tag.secret
+	if rev, ok := value.Interface().(revealer); ok {
+		value, secret = r.ValueOf(rev.reveal()), true
+	}
+
+	return m.formatField(value, f.Tag), secret
+}
+
+// sameValues reports whether old and new hold the same values. it is reflect.DeepEqual except that certificate
+// pools are compared with their Equal method and *tls.Config by the settings TLSConfig fills, since both hold
+// funcs and state DeepEqual never reports as equal
+func sameValues(old, new interface{}) bool {
+	return sameValue(r.ValueOf(old), r.ValueOf(new))
+}
+
+func sameValue(a, b r.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch {
+	case !a.CanInterface():
+		// values of unexported fields are compared below
+	case a.Type() == certPoolType:
+		return a.Interface().(*x509.CertPool).Equal(b.Interface().(*x509.CertPool))
+	case a.Type() == tlsConfigType:
+		return sameTLSConfig(a.Interface().(*tls.Config), b.Interface().(*tls.Config))
+	}
+
+	switch a.Kind() {
+	case r.Pointer, r.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+
+		return sameValue(a.Elem(), b.Elem())
+	case r.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !sameValue(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+
+		return true
+	case r.Slice, r.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+
+		for i := 0; i < a.Len(); i++ {
+			if !sameValue(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+
+		return true
+	case r.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+
+		iter := a.MapRange()
+		for iter.Next() {
+			if !sameValue(iter.Value(), b.MapIndex(iter.Key())) {
+				return false
+			}
+		}
+
+		return true
+	case r.Func:
+		return a.IsNil() == b.IsNil()
+	}
+
+	return a.Equal(b)
+}
+
+// sameTLSConfig compares the settings of the configurations TLSConfig builds, the session state a
+// tls.Config collects while it is used is left out
+func sameTLSConfig(a, b *tls.Config) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if a.ServerName != b.ServerName || a.InsecureSkipVerify != b.InsecureSkipVerify || a.MinVersion != b.MinVersion ||
+		!a.RootCAs.Equal(b.RootCAs) || !a.ClientCAs.Equal(b.ClientCAs) || len(a.Certificates) != len(b.Certificates) {
+		return false
+	}
+
+	for i := range a.Certificates {
+		if !r.DeepEqual(a.Certificates[i].Certificate, b.Certificates[i].Certificate) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,57 @@
+package envs_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func TestDiff(t *testing.T) {
+	type Server struct {
+		Port    int           `env:"PORT"`
+		Timeout time.Duration `env:"TIMEOUT"`
+	}
+
+	type Config struct {
+		Name     string                `env:"NAME"`
+		Server   Server                `env:"SERVER"`
+		Password string                `env:"PASSWORD,secret"`
+		Token    envs.Redacted[string] `env:"TOKEN"`
+		Hosts    []string              `env:"HOSTS"`
+	}
+
+	old := Config{
+		Name:     "svc",
+		Server:   Server{Port: 8080, Timeout: time.Second},
+		Password: "old-password",
+		Token:    envs.NewRedacted("token-1111"),
+		Hosts:    []string{"a"},
+	}
+
+	if got := envs.Diff(old, old); got != nil {
+		t.Errorf("Diff() of equal configurations = %+v, want none", got)
+	}
+
+	changed := old
+	changed.Server.Port = 9090
+	changed.Password = "new-password"
+	changed.Token = envs.NewRedacted("token-2222")
+	changed.Hosts = []string{"a", "b"}
+
+	want := []envs.Change{
+		{Path: "Server.Port", Key: "SERVER_PORT", Old: "8080", New: "9090"},
+		{Path: "Password", Key: "PASSWORD", Old: "****word", New: "****word"},
+		{Path: "Token", Key: "TOKEN", Old: "****1111", New: "****2222"},
+		{Path: "Hosts", Key: "HOSTS", Old: "a", New: "a,b"},
+	}
+
+	if got := envs.Diff(old, &changed); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v, want %+v", got, want)
+	}
+
+	if got := envs.Diff(1, 2); got != nil {
+		t.Errorf("Diff() of non structs = %+v, want none", got)
+	}
+}
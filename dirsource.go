@@ -0,0 +1,111 @@
+package envs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// dockerSecretsDir is where Docker Swarm and Compose mount secrets
+const dockerSecretsDir = "/run/secrets"
+
+// kubeletDataDir is the symlink kubelet swaps atomically when projected volumes are updated
+const kubeletDataDir = "..data"
+
+// DirSource reads values from a directory holding one file per key, like the projected ConfigMap, Secret and
+// downward API volumes of Kubernetes. the files are read once into a snapshot, hidden files are skipped and a
+// missing directory holds no keys, so the source can be layered over others where it is not mounted
+type DirSource struct {
+	// Dir is the directory holding the files
+	Dir string
+	// FileName turns keys into file names, keys are used as they are when it is nil
+	FileName func(key string) string
+	// TrimNewline removes a single trailing newline from the values
+	TrimNewline bool
+	// Reload reads the files again once the ..data symlink kubelet swaps on updates points somewhere else
+	Reload bool
+
+	mu     sync.Mutex
+	values map[string]string
+	target string
+}
+
+// NewDirSource creates a DirSource reading the files in dir
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{Dir: dir}
+}
+
+// NewDockerSecretsSource creates a DirSource reading Docker Swarm and Compose secrets from /run/secrets/<key>
+// with keys in lower case and a trailing newline removed, dir replaces /run/secrets when it is not empty
+func NewDockerSecretsSource(dir string) *DirSource {
+	if dir == "" {
+		dir = dockerSecretsDir
+	}
+
+	return &DirSource{Dir: dir, FileName: strings.ToLower, TrimNewline: true}
+}
+
+// Lookup reads the file of key from the snapshot, reading the directory first when needed
+func (s *DirSource) Lookup(_ context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values == nil || (s.Reload && s.rotated()) {
+		if err := s.read(); err != nil {
+			return "", false, err
+		}
+	}
+
+	if s.FileName != nil {
+		key = s.FileName(key)
+	}
+
+	val, ok := s.values[key]
+	return val, ok, nil
+}
+
+// rotated reports whether the ..data symlink points somewhere else than it did for the snapshot
+func (s *DirSource) rotated() bool {
+	target, _ := os.Readlink(filepath.Join(s.Dir, kubeletDataDir))
+	return target != s.target
+}
+
+func (s *DirSource) read() error {
+	s.target, _ = os.Readlink(filepath.Join(s.Dir, kubeletDataDir))
+
+	entries, err := os.ReadDir(filepath.Clean(s.Dir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(s.Dir, entry.Name())
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+
+		val := string(data)
+		if s.TrimNewline {
+			val = strings.TrimSuffix(strings.TrimSuffix(val, "\n"), "\r")
+		}
+
+		values[entry.Name()] = val
+	}
+
+	s.values = values
+	return nil
+}
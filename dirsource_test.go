@@ -0,0 +1,126 @@
+package envs_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+// projectVolume writes files into a new timestamped directory of dir and swaps the ..data symlink to it like kubelet
+func projectVolume(t *testing.T, dir, version string, files map[string]string) {
+	t.Helper()
+
+	if err := os.Mkdir(filepath.Join(dir, version), 0o700); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, version, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); os.IsNotExist(err) {
+			if err := os.Symlink(filepath.Join("..data", name), link); err != nil {
+				t.Fatalf("Symlink() error = %v", err)
+			}
+		}
+	}
+
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(version, tmp); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+
+	if err := os.Rename(tmp, filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("projected volumes use symlinks")
+	}
+
+	type Config struct {
+		Port  int    `env:"PORT"`
+		Level string `env:"LEVEL,default=info"`
+	}
+
+	dir := t.TempDir()
+	projectVolume(t, dir, "..v1", map[string]string{"app_port": "8080"})
+
+	source := envs.NewDirSource(dir)
+	source.Reload = true
+	source.FileName = func(key string) string { return strings.ToLower(key) }
+	p := envs.NewParserOpts(envs.WithSource(source))
+
+	var got Config
+	if err := p.ParseStruct(&got, "APP"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if want := (Config{Port: 8080, Level: "info"}); got != want {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+
+	projectVolume(t, dir, "..v2", map[string]string{"app_port": "9090", "app_level": "debug"})
+	if err := p.ParseStruct(&got, "APP"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if want := (Config{Port: 9090, Level: "debug"}); got != want {
+		t.Errorf("ParseStruct() after rotation = %+v, want %+v", got, want)
+	}
+
+	static := envs.NewDirSource(dir)
+	if _, found, _ := static.Lookup(context.Background(), "..data"); found {
+		t.Error("Lookup(..data) found a hidden entry")
+	}
+
+	if _, found, err := envs.NewDirSource(filepath.Join(dir, "missing")).Lookup(context.Background(), "PORT"); found ||
+		err != nil {
+		t.Errorf("Lookup() on a missing directory = %v, %v want no value and no error", found, err)
+	}
+
+	if _, _, err := envs.NewDirSource(filepath.Join(dir, "app_port")).Lookup(context.Background(), "PORT"); err == nil {
+		t.Error("Lookup() expected an error for a path that is not a directory")
+	}
+}
+
+func TestDockerSecretsSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "db_password"), []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	type Config struct {
+		Password string `env:"PASSWORD,secret"`
+		User     string `env:"USER,default=admin"`
+	}
+
+	var got Config
+	err := envs.NewParserOpts(envs.WithSource(envs.NewDockerSecretsSource(dir))).ParseStruct(&got, "DB")
+	if err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if want := (Config{Password: "s3cret", User: "admin"}); got != want {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+
+	// outside of docker the secrets are not mounted and no key is found
+	missing := envs.NewDockerSecretsSource(filepath.Join(dir, "missing"))
+	if val, ok, err := missing.Lookup(context.Background(), "DB_PASSWORD"); val != "" || ok || err != nil {
+		t.Errorf("Lookup() on a missing directory = %q, %v, %v want no value and no error", val, ok, err)
+	}
+
+	if got := envs.NewDockerSecretsSource("").Dir; got != "/run/secrets" {
+		t.Errorf("NewDockerSecretsSource(\"\").Dir = %q, want /run/secrets", got)
+	}
+}
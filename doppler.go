@@ -0,0 +1,33 @@
+package envs
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// dopplerDownloadURL is the Doppler API endpoint returning every secret of a config
+const dopplerDownloadURL = "https://api.doppler.com/v3/configs/config/secrets/download"
+
+// NewDopplerSource creates a source reading the secrets of a Doppler config with token, service tokens are bound
+// to a single config so project and config can be left empty with them. the secrets are fetched once,
+// set TTL on the returned source to fetch them again
+func NewDopplerSource(token, project, config string) *HTTPSource {
+	query := url.Values{"format": {"json"}}
+	if project != "" {
+		query.Set("project", project)
+	}
+
+	if config != "" {
+		query.Set("config", config)
+	}
+
+	return &HTTPSource{
+		URL: dopplerDownloadURL + "?" + query.Encode(),
+		Authorize: func(_ context.Context, header http.Header) error {
+			header.Set("Authorization", "Bearer "+token)
+			return nil
+		},
+		KeyFunc: func(path string) string { return path },
+	}
+}
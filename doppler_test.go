@@ -0,0 +1,41 @@
+package envs_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestDopplerSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		if req.Header.Get("Authorization") != "Bearer dp.st.token" || query.Get("format") != "json" ||
+			query.Get("project") != "api" || query.Get("config") != "prd" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"DB_URL": "postgres://db", "DB_POOL": "20", "DOPPLER_CONFIG": "prd"}`))
+	}))
+	defer server.Close()
+
+	type Config struct {
+		URL  string `env:"URL"`
+		Pool int    `env:"POOL"`
+	}
+
+	source := envs.NewDopplerSource("dp.st.token", "api", "prd")
+	source.URL = server.URL + "/v3/configs/config/secrets/download?" + strings.SplitN(source.URL, "?", 2)[1]
+
+	var got Config
+	if err := envs.NewParserOpts(envs.WithSource(source)).ParseStruct(&got, "DB"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if want := (Config{URL: "postgres://db", Pool: 20}); got != want {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,216 @@
+package envs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SOPSCommand is the sops binary LoadDotEnv runs to decrypt SOPS encrypted files
+var SOPSCommand = "sops"
+
+// ReadDotEnv reads KEY=VALUE lines from r in the .env format, blank lines and lines starting with # are skipped,
+// an optional `export` keyword is allowed and values can be single or double quoted. quoted values can span
+// several lines and double quoted ones turn \n, \r, \t, \" and \\ escapes into the characters they stand for,
+// so PEM certificates fit in a single value
+func ReadDotEnv(r io.Reader) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		text = strings.TrimPrefix(text, "export ")
+		key, val, ok := strings.Cut(text, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: %q is not in KEY=VALUE format", line, text)
+		}
+
+		start := line
+		val = strings.TrimSpace(val)
+		for isOpenQuote(val) && scanner.Scan() {
+			line++
+			val += "\n" + strings.TrimRight(scanner.Text(), " \t\r")
+		}
+
+		val, err := parseDotEnvValue(val)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", start, key, err)
+		}
+
+		values[key] = val
+	}
+
+	return values, scanner.Err()
+}
+
+// LoadDotEnv reads the .env files in paths, values of later files override the earlier ones.
+// files encrypted with SOPS, including flat YAML and JSON ones, are decrypted with SOPSCommand, which finds the age
+// or KMS keys the same way it does on the command line
+func LoadDotEnv(paths ...string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, path := range paths {
+		fileValues, err := readDotEnvFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for k, v := range fileValues {
+			values[k] = v
+		}
+	}
+
+	return values, nil
+}
+
+func readDotEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	if isSOPSEncrypted(path, data) {
+		if data, err = decryptSOPS(path); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	values, err := ReadDotEnv(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// isSOPSEncrypted detects the metadata SOPS adds to the files it encrypts, sops_ keys in .env files and a top level
+// sops key in YAML and JSON ones
+func isSOPSEncrypted(path string, data []byte) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return bytes.HasPrefix(data, []byte("sops:")) || bytes.Contains(data, []byte("\nsops:"))
+	case ".json":
+		return bytes.Contains(data, []byte(`"sops":`))
+	default:
+		return bytes.Contains(data, []byte("\nsops_mac=")) || bytes.HasPrefix(data, []byte("sops_mac="))
+	}
+}
+
+// decryptSOPS decrypts the file at path into the .env format
+func decryptSOPS(path string) ([]byte, error) {
+	var stderr bytes.Buffer
+	// #nosec G204 -- the file path is passed as a single argument to the configured sops binary
+	cmd := exec.Command(SOPSCommand, "--decrypt", "--output-type", "dotenv", path)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("sops: %w: %s", err, msg)
+		}
+
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+
+	return out, nil
+}
+
+// isOpenQuote reports whether val starts a quoted value that does not end on the same line,
+// quotes in a comment after the closing one do not open it again
+func isOpenQuote(val string) bool {
+	if val == "" || (val[0] != '"' && val[0] != '\'') {
+		return false
+	}
+
+	return closingQuote(val) < 0
+}
+
+// closingQuote returns the index of the first quote closing the value val starts with, or -1,
+// double quotes escaped with a backslash do not close it. quotes after it belong to a comment
+func closingQuote(val string) int {
+	quote := val[0]
+	for i := 1; i < len(val); i++ {
+		switch {
+		case quote == '"' && val[i] == '\\':
+			i++
+		case val[i] == quote:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// parseDotEnvValue removes the quotes around val, unquoted values end at an inline ` #` comment
+func parseDotEnvValue(val string) (string, error) {
+	if val == "" {
+		return "", nil
+	}
+
+	quote := val[0]
+	if quote != '"' && quote != '\'' {
+		if i := strings.Index(val, " #"); i >= 0 {
+			val = val[:i]
+		}
+
+		return strings.TrimSpace(val), nil
+	}
+
+	end := closingQuote(val)
+	if end < 0 {
+		return "", fmt.Errorf("missing closing quote %c", quote)
+	}
+
+	if rest := strings.TrimSpace(val[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected %q after closing quote", rest)
+	}
+
+	if quote == '"' {
+		return unescape(val[1:end]), nil
+	}
+
+	return val[1:end], nil
+}
+
+// unescape turns \n, \r, \t, \" and \\ in val into the characters they stand for, other backslashes are kept
+func unescape(val string) string {
+	if !strings.Contains(val, "\\") {
+		return val
+	}
+
+	var b strings.Builder
+	b.Grow(len(val))
+	for i := 0; i < len(val); i++ {
+		if val[i] != '\\' || i == len(val)-1 {
+			b.WriteByte(val[i])
+			continue
+		}
+
+		switch val[i+1] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '"', '\\':
+			b.WriteByte(val[i+1])
+		default:
+			b.WriteByte(val[i])
+			continue
+		}
+
+		i++
+	}
+
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package envs_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestReadDotEnv(t *testing.T) {
+	input := `# comment
+APP_NAME=svc
+export APP_PORT = 8080
+
+APP_SINGLE='it has # and spaces '
+APP_DOUBLE="quoted value" # trailing comment
+APP_QUOTED_COMMENT="a" # say "hi"
+APP_SINGLE_COMMENT='x' # don't
+APP_INLINE=value # comment
+APP_EMPTY=
+APP_ESCAPED="a\nb\t\"c\" C:\\dir"
+APP_LITERAL='a\nb'
+APP_CERT="-----BEGIN CERTIFICATE-----
+  MIIB
+-----END CERTIFICATE-----"
+APP_MULTI="first
+second" # the "end" of it
+APP_AFTER=after
+`
+	want := map[string]string{
+		"APP_NAME":           "svc",
+		"APP_PORT":           "8080",
+		"APP_SINGLE":         "it has # and spaces ",
+		"APP_DOUBLE":         "quoted value",
+		"APP_QUOTED_COMMENT": "a",
+		"APP_SINGLE_COMMENT": "x",
+		"APP_INLINE":         "value",
+		"APP_EMPTY":          "",
+		"APP_ESCAPED":        "a\nb\t\"c\" C:\\dir",
+		"APP_LITERAL":        `a\nb`,
+		"APP_CERT":           "-----BEGIN CERTIFICATE-----\n  MIIB\n-----END CERTIFICATE-----",
+		"APP_MULTI":          "first\nsecond",
+		"APP_AFTER":          "after",
+	}
+
+	got, err := envs.ReadDotEnv(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadDotEnv() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadDotEnv() = %v, want %v", got, want)
+	}
+
+	for _, input := range []string{"NO_EQUAL_SIGN", "=value", "A B=c", `A="unterminated`, `A="x" y`, "A=\"open\nB=c"} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := envs.ReadDotEnv(strings.NewReader(input)); err == nil {
+				t.Errorf("ReadDotEnv(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestLoadDotEnv(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+
+	if err := os.WriteFile(base, []byte("A=1\nB=2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(local, []byte("B=3\nC=4\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := envs.LoadDotEnv(base, local)
+	if err != nil {
+		t.Fatalf("LoadDotEnv() error = %v", err)
+	}
+
+	want := map[string]string{"A": "1", "B": "3", "C": "4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadDotEnv() = %v, want %v", got, want)
+	}
+
+	if _, err := envs.LoadDotEnv(filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadDotEnv() expected an error for a missing file")
+	}
+}
+
+func TestLoadDotEnvSOPS(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake sops binary is a shell script")
+	}
+
+	dir := t.TempDir()
+	sops := filepath.Join(dir, "sops")
+	script := "#!/bin/sh\n[ \"$1 $2 $3\" = \"--decrypt --output-type dotenv\" ] || exit 1\n" +
+		"case \"$4\" in *.yaml) echo DB_PASSWORD=from-yaml ;; *) echo DB_PASSWORD=from-env ;; esac\n"
+	if err := os.WriteFile(sops, []byte(script), 0o700); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	old := envs.SOPSCommand
+	envs.SOPSCommand = sops
+	t.Cleanup(func() { envs.SOPSCommand = old })
+
+	files := map[string]string{
+		"plain.env":    "DB_USER=admin\n",
+		"secrets.env":  "DB_PASSWORD=ENC[AES256_GCM,data:abc,type:str]\nsops_mac=ENC[AES256_GCM,data:def,type:str]\n",
+		"secrets.yaml": "DB_PASSWORD: ENC[AES256_GCM,data:abc,type:str]\nsops:\n    mac: ENC[AES256_GCM,data:def]\n",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	tests := map[string]map[string]string{
+		"secrets.env":  {"DB_USER": "admin", "DB_PASSWORD": "from-env"},
+		"secrets.yaml": {"DB_USER": "admin", "DB_PASSWORD": "from-yaml"},
+	}
+
+	for name, want := range tests {
+		got, err := envs.LoadDotEnv(filepath.Join(dir, "plain.env"), filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("LoadDotEnv(%s) error = %v", name, err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("LoadDotEnv(%s) = %v, want %v", name, got, want)
+		}
+	}
+
+	envs.SOPSCommand = filepath.Join(dir, "missing")
+	if _, err := envs.LoadDotEnv(filepath.Join(dir, "secrets.env")); err == nil {
+		t.Error("LoadDotEnv() expected an error without a sops binary")
+	}
+}
@@ -0,0 +1,128 @@
+package envs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DotEnvSource reads values from .env files, later files override earlier ones like LoadDotEnv. the files are read
+// again after they were written or replaced and it implements Waiter, so a Watcher using it reloads on every edit.
+// changes are found by polling the modification times and sizes of the files instead of file system
+// notifications, which keeps the package free of dependencies and also works on network and container mounts
+// where notifications are not delivered. the files are checked at most once per PollInterval, not on every lookup
+type DotEnvSource struct {
+	// Paths are the .env files to read
+	Paths []string
+	// PollInterval is how often the files are checked for changes, a second by default
+	PollInterval time.Duration
+
+	mu      sync.Mutex
+	values  map[string]string
+	version string
+	checked time.Time
+	waited  string
+}
+
+// NewDotEnvSource creates a DotEnvSource reading the .env files in paths
+func NewDotEnvSource(paths ...string) *DotEnvSource {
+	return &DotEnvSource{Paths: paths}
+}
+
+// Lookup reads key from the files, reading them first when they changed since they were last checked
+func (s *DotEnvSource) Lookup(_ context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values == nil || time.Since(s.checked) >= s.interval() {
+		if err := s.load(s.stat()); err != nil {
+			return "", false, err
+		}
+	}
+
+	val, ok := s.values[key]
+	return val, ok, nil
+}
+
+// Wait blocks until one of the files is written, replaced or removed and reads them again before it returns,
+// so the parse that follows sees the new values. the first call compares them with the last lookup and returns
+// right away when there was none
+func (s *DotEnvSource) Wait(ctx context.Context) error {
+	s.mu.Lock()
+	if s.waited == "" {
+		s.waited = s.version
+	}
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(s.interval())
+	defer ticker.Stop()
+
+	for {
+		version := s.stat()
+
+		s.mu.Lock()
+		changed := version != s.waited
+		s.waited = version
+		if changed {
+			// a failed read is left to the next lookup to report
+			if err := s.load(version); err != nil {
+				s.values = nil
+			}
+		}
+		s.mu.Unlock()
+
+		if changed {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *DotEnvSource) interval() time.Duration {
+	if s.PollInterval <= 0 {
+		return time.Second
+	}
+
+	return s.PollInterval
+}
+
+// load reads the files when version, their state from stat, differs from the one they were read at
+func (s *DotEnvSource) load(version string) error {
+	s.checked = time.Now()
+	if s.values != nil && version == s.version {
+		return nil
+	}
+
+	values, err := LoadDotEnv(s.Paths...)
+	if err != nil {
+		return err
+	}
+
+	s.values, s.version = values, version
+	return nil
+}
+
+// stat describes the modification time and size of every file, missing files are described as such
+func (s *DotEnvSource) stat() string {
+	var b strings.Builder
+	for _, path := range s.Paths {
+		info, err := os.Stat(filepath.Clean(path))
+		if err != nil {
+			fmt.Fprintf(&b, "%s:missing;", path)
+			continue
+		}
+
+		fmt.Fprintf(&b, "%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+	}
+
+	return b.String()
+}
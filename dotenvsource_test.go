@@ -0,0 +1,95 @@
+package envs_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func TestDotEnvSource(t *testing.T) {
+	type Config struct {
+		Port  int    `env:"PORT"`
+		Level string `env:"LEVEL,default=info"`
+	}
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("APP_PORT=8080\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	source := envs.NewDotEnvSource(path)
+	source.PollInterval = 5 * time.Millisecond
+
+	w, err := envs.NewWatcher[Config]("APP", 0, envs.WithSource(source))
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	changes := make(chan Config, 10)
+	w.OnChange(func(_, new Config) { changes <- new })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = w.Run(ctx) }()
+
+	// replace the file like editors saving atomically do
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte("APP_PORT=9090\nAPP_LEVEL=debug\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+
+	select {
+	case got := <-changes:
+		if want := (Config{Port: 9090, Level: "debug"}); got != want {
+			t.Errorf("OnChange() got %+v, want %+v", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change reported after the file was replaced")
+	}
+
+	var got Config
+	if err := envs.NewParserOpts(envs.WithSource(source)).ParseStruct(&got, "APP"); err != nil || got.Port != 9090 {
+		t.Errorf("ParseStruct() = %+v, %v, want port 9090", got, err)
+	}
+}
+
+func TestDotEnvSource_checksOncePerInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("APP_PORT=8080\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	source := envs.NewDotEnvSource(path)
+	source.PollInterval = time.Hour
+
+	ctx := context.Background()
+	if got, _, err := source.Lookup(ctx, "APP_PORT"); got != "8080" || err != nil {
+		t.Fatalf("Lookup() = %q, %v want %q", got, err, "8080")
+	}
+
+	if err := os.WriteFile(path, []byte("APP_PORT=10000\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	// the files are not checked again on every lookup within the interval
+	if got, _, _ := source.Lookup(ctx, "APP_PORT"); got != "8080" {
+		t.Errorf("Lookup() = %q, want the cached %q", got, "8080")
+	}
+
+	if err := source.Wait(ctx); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	if got, _, _ := source.Lookup(ctx, "APP_PORT"); got != "10000" {
+		t.Errorf("Lookup() after Wait() = %q, want %q", got, "10000")
+	}
+}
@@ -0,0 +1,163 @@
+package envs
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"net/url"
+	r "reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Dump writes the configuration in cfg as aligned KEY = value lines, values of fields tagged as `secret`
+// are masked. keys are built the same way NewParser(nil, nil).ParseStruct(&cfg, "") reads them.
+func Dump(cfg interface{}, w io.Writer) error {
+	return NewParser(nil, nil).Dump(cfg, w)
+}
+
+// Dump writes the configuration in cfg as aligned KEY = value lines using the parser prefix and key function,
+// values of fields tagged as `secret` are masked.
+func (m *Parser) Dump(cfg interface{}, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+
+	err := m.walk(r.ValueOf(cfg), m.prefix, "", func(f field) error {
+		val := m.formatField(f.Value, f.Tag)
+		if f.Tag.secret {
+			val = mask(val)
+		}
+
+		_, err := fmt.Fprintf(tw, "%s\t= %s\n", f.Key, val)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return tw.Flush()
+}
+
+// format turns v back into the string form the parser reads
+func (m *Parser) format(v r.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+
+	switch v.Type() {
+	case timeType:
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.Format(time.RFC3339Nano)
+	case durationType:
+		return v.Interface().(time.Duration).String()
+	case fileModeType:
+		return "0" + strconv.FormatUint(v.Uint(), 8)
+	case signerType, rsaKeyType, ecdsaKeyType, ed25519KeyType:
+		return formatKey(v)
+	case certPoolType:
+		// pools do not keep the PEM data they were built from
+		return ""
+	case urlType.Elem():
+		u := v.Interface().(url.URL)
+		return u.String()
+	}
+
+	if v.Kind() == r.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+
+		return m.format(v.Elem())
+	}
+
+	marshaler, ok := v.Interface().(encoding.TextMarshaler)
+	if !ok && v.CanAddr() {
+		marshaler, ok = v.Addr().Interface().(encoding.TextMarshaler)
+	}
+
+	if ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case r.String:
+		return v.String()
+	case r.Int, r.Int8, r.Int16, r.Int32, r.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case r.Uint, r.Uint8, r.Uint16, r.Uint32, r.Uint64, r.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case r.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case r.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case r.Complex64, r.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits())
+	case r.Bool:
+		return strconv.FormatBool(v.Bool())
+	case r.Slice, r.Array:
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, m.format(v.Index(i)))
+		}
+
+		return strings.Join(items, m.listSeparator())
+	case r.Map:
+		pairs := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			pairs = append(pairs, m.format(iter.Key())+":"+m.formatMapValue(iter.Value()))
+		}
+
+		sort.Strings(pairs)
+		return strings.Join(pairs, m.listSeparator())
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// formatMapValue formats a map value, lists are joined with mapListSeparator so parseMap can read them again
+func (m *Parser) formatMapValue(v r.Value) string {
+	if !isMapList(v.Type()) {
+		return m.format(v)
+	}
+
+	items := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		items = append(items, m.format(v.Index(i)))
+	}
+
+	return strings.Join(items, mapListSeparator)
+}
+
+// formatField formats the value v of a field with the tag options opts, `octal` integers are written in base 8
+func (m *Parser) formatField(v r.Value, opts tagOptions) string {
+	if !opts.octal || !v.IsValid() {
+		return m.format(v)
+	}
+
+	switch v.Kind() {
+	case r.Int, r.Int8, r.Int16, r.Int32, r.Int64:
+		return "0" + strconv.FormatInt(v.Int(), 8)
+	case r.Uint, r.Uint8, r.Uint16, r.Uint32, r.Uint64, r.Uintptr:
+		return "0" + strconv.FormatUint(v.Uint(), 8)
+	}
+
+	return m.format(v)
+}
+
+// listSeparator is the separator used when formatting slices and maps
+func (m *Parser) listSeparator() string {
+	if len(m.separators) == 0 {
+		return stringSeparators[0]
+	}
+
+	return m.separators[0]
+}
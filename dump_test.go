@@ -0,0 +1,61 @@
+package envs_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func TestDump(t *testing.T) {
+	type Config struct {
+		Name   string            `env:"NAME"`
+		Token  string            `env:"TOKEN,secret"`
+		Labels map[string]string `env:"LABELS"`
+		Hosts  []string          `env:"HOSTS"`
+		Server struct {
+			Port    int           `env:"PORT"`
+			Timeout time.Duration `env:"TIMEOUT"`
+		} `env:"SERVER"`
+	}
+
+	cfg := Config{
+		Name:   "svc",
+		Token:  "super-secret-1234",
+		Labels: map[string]string{"tier": "1", "team": "core"},
+		Hosts:  []string{"a", "b"},
+	}
+	cfg.Server.Port = 8080
+	cfg.Server.Timeout = 2 * time.Second
+
+	want := `NAME           = svc
+TOKEN          = ****1234
+LABELS         = team:core,tier:1
+HOSTS          = a,b
+SERVER_PORT    = 8080
+SERVER_TIMEOUT = 2s
+`
+
+	buf := &bytes.Buffer{}
+	if err := envs.Dump(&cfg, buf); err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Errorf("Dump() got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := envs.NewParserOpts(envs.WithPrefix("APP")).Dump(cfg, buf); err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+
+	if got := buf.String(); !bytes.HasPrefix([]byte(got), []byte("APP_NAME ")) {
+		t.Errorf("Dump() got:\n%s\nwant APP_ prefixed keys", got)
+	}
+
+	if err := envs.Dump(1, buf); err == nil {
+		t.Errorf("Dump() expected error for non struct value")
+	}
+}
@@ -0,0 +1,98 @@
+package envs
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environ returns the variables of the process under prefix with the prefix stripped, like PORT for APP_PORT.
+// the key function of NewParser(nil, nil) joins prefix to the keys, an empty prefix returns every variable.
+func Environ(prefix string) map[string]string {
+	return NewParser(nil, nil).Environ(prefix)
+}
+
+// Environ returns the variables of the process under prefix with the prefix stripped, the parser key function
+// decides how prefix is joined to the keys. an empty prefix falls back to the one set with WithPrefix
+func (m *Parser) Environ(prefix string) map[string]string {
+	if prefix == "" {
+		prefix = m.prefix
+	}
+
+	head := ""
+	if prefix != "" {
+		head = m.BuildKey(prefix + ".")
+	}
+
+	environ := os.Environ()
+	values := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		k, v := cutEnv(kv)
+		if key, ok := strings.CutPrefix(k, head); ok && key != "" && !isDriveDir(k) {
+			values[key] = v
+		}
+	}
+
+	return values
+}
+
+// Env is a copy of the process environment taken by Snapshot
+type Env map[string]string
+
+// Snapshot copies the whole process environment, so tools that change it like exec helpers and test harnesses
+// can put it back with Restore. the per drive directories windows lists as =C:=C:\dir are left out since they
+// can not be set
+func Snapshot() Env {
+	environ := os.Environ()
+	env := make(Env, len(environ))
+	for _, kv := range environ {
+		if k, v := cutEnv(kv); !isDriveDir(k) {
+			env[k] = v
+		}
+	}
+
+	return env
+}
+
+// Restore makes the process environment equal to the snapshot again, variables set since are unset and
+// changed or unset ones get their old values back
+func (e Env) Restore() error {
+	for k := range Snapshot() {
+		if _, ok := e[k]; !ok {
+			if err := os.Unsetenv(k); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+		}
+	}
+
+	for k, v := range e {
+		if cur, ok := os.LookupEnv(k); ok && cur == v {
+			continue
+		}
+
+		if err := os.Setenv(k, v); err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+	}
+
+	return nil
+}
+
+// cutEnv splits a KEY=VALUE pair of an environment list on the first = after the name,
+// so windows entries like =C:=C:\dir keep =C: as their name
+func cutEnv(kv string) (key, value string) {
+	if i := strings.IndexByte(kv, '='); i > 0 {
+		return kv[:i], kv[i+1:]
+	}
+
+	if i := strings.IndexByte(kv[min(len(kv), 1):], '='); i >= 0 {
+		return kv[:i+1], kv[i+2:]
+	}
+
+	return kv, ""
+}
+
+// isDriveDir reports whether key names one of the hidden per drive directories of windows
+func isDriveDir(key string) bool {
+	return strings.HasPrefix(key, "=")
+}
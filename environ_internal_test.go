@@ -0,0 +1,23 @@
+package envs
+
+import "testing"
+
+func TestCutEnv(t *testing.T) {
+	tests := []struct {
+		kv, key, value string
+	}{
+		{"A=1", "A", "1"},
+		{"A=b=c", "A", "b=c"},
+		{"A=", "A", ""},
+		{"A", "A", ""},
+		{`=C:=C:\app`, "=C:", `C:\app`},
+		{"=", "=", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		if key, value := cutEnv(tt.kv); key != tt.key || value != tt.value {
+			t.Errorf("cutEnv(%q) = %q, %q want %q, %q", tt.kv, key, value, tt.key, tt.value)
+		}
+	}
+}
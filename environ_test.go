@@ -0,0 +1,64 @@
+package envs_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestEnviron(t *testing.T) {
+	t.Setenv("ENVIRON_PORT", "8080")
+	t.Setenv("ENVIRON_SERVER_HOST", "localhost")
+	t.Setenv("ENVIRONMENT", "prod")
+	t.Setenv("ENVIRON__NESTED__KEY", "value")
+
+	want := map[string]string{"PORT": "8080", "SERVER_HOST": "localhost", "_NESTED__KEY": "value"}
+	if got := envs.Environ("ENVIRON"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Environ() = %v, want %v", got, want)
+	}
+
+	// the delimiter joins nested fields only, the prefix keeps its _
+	p := envs.NewParserOpts(envs.WithPrefix("ENVIRON"), envs.WithDelimiter("__"))
+	if got := p.Environ(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parser.Environ() = %v, want %v", got, want)
+	}
+
+	if got := envs.Environ(""); got["ENVIRONMENT"] != "prod" {
+		t.Errorf("Environ(\"\") = %v, want every variable", got)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Setenv("SNAPSHOT_KEPT", "1")
+	t.Setenv("SNAPSHOT_CHANGED", "before")
+	t.Setenv("SNAPSHOT_REMOVED", "gone")
+	t.Setenv("SNAPSHOT_ADDED", "")
+	_ = os.Unsetenv("SNAPSHOT_ADDED")
+
+	snap := envs.Snapshot()
+
+	_ = os.Setenv("SNAPSHOT_CHANGED", "after")
+	_ = os.Unsetenv("SNAPSHOT_REMOVED")
+	_ = os.Setenv("SNAPSHOT_ADDED", "new")
+
+	if err := snap.Restore(); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	want := map[string]string{"SNAPSHOT_KEPT": "1", "SNAPSHOT_CHANGED": "before", "SNAPSHOT_REMOVED": "gone"}
+	for key, val := range want {
+		if got, ok := os.LookupEnv(key); !ok || got != val {
+			t.Errorf("%s = %q, %v after Restore(), want %q", key, got, ok, val)
+		}
+	}
+
+	if _, ok := os.LookupEnv("SNAPSHOT_ADDED"); ok {
+		t.Error("SNAPSHOT_ADDED is still set after Restore()")
+	}
+
+	if !reflect.DeepEqual(envs.Snapshot(), snap) {
+		t.Error("Snapshot() after Restore() differs from the restored snapshot")
+	}
+}
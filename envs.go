@@ -1,7 +1,9 @@
 package envs
 
 import (
+	"encoding"
 	"fmt"
+	"net/url"
 	"os"
 	"reflect"
 	"strconv"
@@ -10,61 +12,241 @@ import (
 )
 
 var (
-	separators  = []string{" ", ",", ";", "\n"}
-	timeLayouts = []string{time.RFC3339, time.RFC3339Nano, time.DateTime, time.Stamp, time.DateOnly,
+	// genericSeparators split the values of the generic getters, unlike struct fields `-` is not one of them
+	// so negative numbers, dates and host names stay whole
+	genericSeparators = []string{",", ";", " ", "\n"}
+	// genericTimeFormats are the layouts the generic getters accept for time values
+	genericTimeFormats = []string{time.RFC3339, time.RFC3339Nano, time.DateTime, time.Stamp, time.DateOnly,
 		time.TimeOnly, time.ANSIC, time.RFC822, time.UnixDate,
 	}
+
+	// genericParser backs the generic getters for values that need the struct parsing logic
+	genericParser = newGenericParser()
 )
 
+func newGenericParser() *Parser {
+	p := NewParser(nil, nil)
+	p.separators = genericSeparators
+	p.timeFormats = genericTimeFormats
+
+	return p
+}
+
 // Methods can not be generic so I have to wrap everything
 type Getter struct {
-	key func(name string) string
+	key   func(name string) string
+	value ValueFunc
+}
+
+// NewGetter creates a Getter that prefixes names with prefix and reads them using valueFunc,
+// a nil valueFunc reads from the process environment.
+func NewGetter(prefix string, valueFunc ValueFunc) *Getter {
+	if valueFunc == nil {
+		valueFunc = DefaultGetFunc
+	}
+
+	return &Getter{key: MakeKeyProviderPrefix(prefix), value: valueFunc}
+}
+
+// NewMapGetter creates a Getter that reads values from a copy of values instead of the process environment,
+// names are used as keys without any prefix.
+func NewMapGetter(values map[string]string) *Getter {
+	return &Getter{value: FromMap(values)}
 }
 
 func (a *Getter) GetString(name, def string) string {
-	return GetDefault(a.key(name), def)
+	return getOr(a, name, def)
 }
 
 func (a *Getter) GetStringSlice(name string) []string {
-	return GetDefault(a.key(name), []string{})
+	return getOr(a, name, []string{})
 }
 
 func (a *Getter) GetInt(name string, def int) int {
-	return GetDefault(a.key(name), def)
+	return getOr(a, name, def)
 }
 
 func (a *Getter) GetInt64(name string, def int64) int64 {
-	return GetDefault(a.key(name), def)
+	return getOr(a, name, def)
 }
 
 func (a *Getter) GetInt32(name string, def int32) int32 {
-	return GetDefault(a.key(name), def)
+	return getOr(a, name, def)
 }
 
 func (a *Getter) GetFloat64(name string, def float64) float64 {
-	return GetDefault(a.key(name), def)
+	return getOr(a, name, def)
 }
 
 func (a *Getter) GetFloat32(name string, def float32) float32 {
-	return GetDefault(a.key(name), def)
+	return getOr(a, name, def)
 }
 
 func (a *Getter) GetBool(name string) bool {
-	return GetDefault(a.key(name), false)
+	return getOr(a, name, false)
 }
 
 func (a *Getter) GetTime(name string) time.Time {
-	return GetDefault(a.key(name), time.Time{})
+	return getOr(a, name, time.Time{})
 }
 
 func (a *Getter) GetDuration(name string, def time.Duration) time.Duration {
-	return GetDefault(a.key(name), def)
+	return getOr(a, name, def)
+}
+
+func (a *Getter) GetUint(name string, def uint) uint {
+	return getOr(a, name, def)
+}
+
+func (a *Getter) GetUint64(name string, def uint64) uint64 {
+	return getOr(a, name, def)
+}
+
+// GetURL returns nil when name is not set or is not a valid url
+func (a *Getter) GetURL(name string) *url.URL {
+	return getOr[*url.URL](a, name, nil)
+}
+
+func (a *Getter) GetIntSlice(name string) []int {
+	return getOr(a, name, []int{})
+}
+
+func (a *Getter) GetDurationSlice(name string) []time.Duration {
+	return getOr(a, name, []time.Duration{})
+}
+
+// GetStringMap reads values in the `key1:val1,key2:val2` format
+func (a *Getter) GetStringMap(name string) map[string]string {
+	return getOr(a, name, map[string]string{})
+}
+
+// GetBytes returns the raw value of name as bytes without any parsing
+func (a *Getter) GetBytes(name string) []byte {
+	val := a.lookup(a.buildKey(name))
+	if val == "" {
+		return nil
+	}
+
+	return []byte(val)
+}
+
+func (a *Getter) GetStringSliceE(name string) ([]string, error) {
+	return getOrErr(a, name, []string{})
 }
 
+func (a *Getter) GetIntE(name string, def int) (int, error) {
+	return getOrErr(a, name, def)
+}
+
+func (a *Getter) GetInt64E(name string, def int64) (int64, error) {
+	return getOrErr(a, name, def)
+}
+
+func (a *Getter) GetInt32E(name string, def int32) (int32, error) {
+	return getOrErr(a, name, def)
+}
+
+func (a *Getter) GetFloat64E(name string, def float64) (float64, error) {
+	return getOrErr(a, name, def)
+}
+
+func (a *Getter) GetFloat32E(name string, def float32) (float32, error) {
+	return getOrErr(a, name, def)
+}
+
+func (a *Getter) GetBoolE(name string) (bool, error) {
+	return getOrErr(a, name, false)
+}
+
+func (a *Getter) GetTimeE(name string) (time.Time, error) {
+	return getOrErr(a, name, time.Time{})
+}
+
+func (a *Getter) GetDurationE(name string, def time.Duration) (time.Duration, error) {
+	return getOrErr(a, name, def)
+}
+
+func (a *Getter) GetUintE(name string, def uint) (uint, error) {
+	return getOrErr(a, name, def)
+}
+
+func (a *Getter) GetUint64E(name string, def uint64) (uint64, error) {
+	return getOrErr(a, name, def)
+}
+
+func (a *Getter) GetURLE(name string) (*url.URL, error) {
+	return getOrErr[*url.URL](a, name, nil)
+}
+
+func (a *Getter) GetIntSliceE(name string) ([]int, error) {
+	return getOrErr(a, name, []int{})
+}
+
+func (a *Getter) GetDurationSliceE(name string) ([]time.Duration, error) {
+	return getOrErr(a, name, []time.Duration{})
+}
+
+func (a *Getter) GetStringMapE(name string) (map[string]string, error) {
+	return getOrErr(a, name, map[string]string{})
+}
+
+// getOr reads name through the getter and parses it as T, def is returned when the value is missing or invalid
+func getOr[T any](a *Getter, name string, def T) T {
+	res, err := getOrErr(a, name, def)
+	if err != nil {
+		return def
+	}
+
+	return res
+}
+
+// getOrErr reads name through the getter and parses it as T, def is only returned when the value is missing
+// and malformed values are reported as errors.
+func getOrErr[T any](a *Getter, name string, def T) (T, error) {
+	key := a.buildKey(name)
+
+	val := a.lookup(key)
+	if val == "" {
+		emit(Event{Kind: EventUnset, Key: key})
+		return def, nil
+	}
+
+	res, err := parse[T](val)
+	if err != nil {
+		emit(Event{Kind: EventParseError, Key: key, Err: err})
+		return res, fmt.Errorf("%s: %w", key, err)
+	}
+
+	return res, nil
+}
+
+func (a *Getter) buildKey(name string) string {
+	if a.key == nil {
+		return name
+	}
+
+	return a.key(name)
+}
+
+func (a *Getter) lookup(key string) string {
+	if a.value == nil {
+		return DefaultGetFunc(key, "")
+	}
+
+	return a.value(key, "")
+}
+
+// GetDefault returns the value of name as T or def when name is not set,
+// it behaves exactly like GetOr.
 func GetDefault[T any](name string, def T) T {
-	val := Get[T](name)
+	return GetOr(name, def)
+}
 
-	if reflect.ValueOf(val).IsZero() {
+// GetOr returns the value of name as T, def is only used when name is not set or its value can not be parsed as T,
+// so a set zero value like `RETRIES=0` or `VERBOSE=false` is returned as is.
+func GetOr[T any](name string, def T) T {
+	val, ok := Lookup[T](name)
+	if !ok {
 		return def
 	}
 
@@ -72,78 +254,176 @@ func GetDefault[T any](name string, def T) T {
 }
 
 func Get[T any](name string) T {
-	tp := reflect.TypeFor[T]()
-	var res any
+	res, _ := GetErr[T](name)
+	return res
+}
+
+// GetErr returns the value of name as T, the returned error wraps ErrNotSet when name is not set
+// and describes the failure when the value can not be parsed as T.
+func GetErr[T any](name string) (T, error) {
+	val, ok := os.LookupEnv(name)
+	if !ok {
+		emit(Event{Kind: EventUnset, Key: name})
+
+		var zero T
+		return zero, fmt.Errorf("%s: %w", name, ErrNotSet)
+	}
+
+	res, err := parse[T](val)
+	if err != nil {
+		emit(Event{Kind: EventParseError, Key: name, Err: err})
+		return res, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return res, nil
+}
 
-	val := os.Getenv(name)
+// Lookup returns the value of name as T, the boolean is true only when name is set
+// and its value could be parsed as T, so `PORT=0` can be told apart from an unset PORT.
+func Lookup[T any](name string) (T, bool) {
+	res, err := GetErr[T](name)
+	return res, err == nil
+}
+
+// MustGet returns the value of name as T and panics when the value is not set or can not be parsed as T
+func MustGet[T any](name string) T {
+	res, err := GetErr[T](name)
+	if err != nil {
+		panic("envs: " + err.Error())
+	}
+
+	return res
+}
+
+// MustParse parses environment variables with the given prefix into a new T and panics on failure
+func MustParse[T any](prefix string, opts ...Option) T {
+	var res T
+	if err := NewParserOpts(opts...).ParseStruct(&res, prefix); err != nil {
+		panic(fmt.Sprintf("envs: parsing %T: %v", res, err))
+	}
+
+	return res
+}
+
+// parse converts val into T, an empty val results in the zero value of T
+func parse[T any](val string) (T, error) {
+	var zero T
 	if val == "" {
-		return reflect.New(tp).Elem().Interface().(T)
+		return zero, nil
+	}
+
+	res, err := parseType(reflect.TypeFor[T](), val)
+	if err != nil {
+		return zero, err
+	}
+
+	return res.(T), nil
+}
+
+// parseType converts val into a value of type tp
+//
+//nolint:funlen
+func parseType(tp reflect.Type, val string) (any, error) {
+	var (
+		res any
+		err error
+	)
+
+	switch {
+	case tp == timeType:
+		return parseTimeIn(val, genericTimeFormats)
+	case tp == levelType:
+		return ParseLevel(val)
+	case tp == certPoolType:
+		return ParseCertPool(val)
+	case isKeyType(tp):
+		key := reflect.New(tp).Elem()
+		if err = parseKeyValue(key, val); err != nil {
+			return nil, err
+		}
+
+		return key.Interface(), nil
+	case reflect.PointerTo(tp).Implements(textUnmarshalerType):
+		ptr := reflect.New(tp)
+		if err = ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val)); err != nil {
+			return nil, err
+		}
+
+		return ptr.Elem().Interface(), nil
 	}
 
 	switch tp.Kind() {
 	case reflect.String:
 		res = val
-	case reflect.Slice:
-		if tp.Elem().Kind() == reflect.String {
-			for _, sep := range separators {
-				split := strings.Split(val, sep)
-				if split[0] != val {
-					res = split
-					break
-				}
-			}
+	case reflect.Pointer:
+		// pointers are parsed as their element type and returned as its address
+		res, err = parseType(tp.Elem(), val)
+		if err != nil {
+			return nil, err
 		}
 
-		if tp.Elem().Kind() == reflect.Int {
-			split := strings.Split(val, ",")
-			arr := make([]int, 0)
-			for _, str := range split {
-				arr = append(arr, int(parseInt64(str)))
-			}
-			res = arr
-		}
-	case reflect.Int:
-		res = int(parseInt64(val))
-	case reflect.Int32:
-		res = int32(parseInt64(val))
-	case reflect.Int64:
-		res = parseInt64(val)
+		ptr := reflect.New(tp.Elem())
+		ptr.Elem().Set(reflect.ValueOf(res))
+		res = ptr.Interface()
+	case reflect.Slice:
+		// slice elements go through the same path as struct fields
+		slice := reflect.New(tp).Elem()
+		err = genericParser.parseArray(&decodeState{}, val, slice, "")
+		res = slice.Interface()
+	case reflect.Map:
+		// maps use the same key:value format as struct fields
+		m := reflect.New(tp).Elem()
+		err = genericParser.parseMap(&decodeState{}, m, val)
+		res = m.Interface()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(strings.TrimSpace(val), 10, tp.Bits())
+		res = reflect.ValueOf(n).Convert(tp).Interface()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		n, err = strconv.ParseUint(strings.TrimSpace(val), 10, tp.Bits())
+		res = reflect.ValueOf(n).Convert(tp).Interface()
 	case reflect.Float64:
-		res, _ = strconv.ParseFloat(val, 64)
+		res, err = strconv.ParseFloat(val, 64)
 	case reflect.Float32:
-		res, _ = strconv.ParseFloat(val, 32)
+		var f float64
+		f, err = strconv.ParseFloat(val, 32)
+		res = float32(f)
+	case reflect.Complex64, reflect.Complex128:
+		var c complex128
+		c, err = strconv.ParseComplex(strings.TrimSpace(val), tp.Bits())
+		res = reflect.ValueOf(c).Convert(tp).Interface()
 	case reflect.Bool:
-		res, _ = strconv.ParseBool(val)
+		res, err = strconv.ParseBool(val)
 	}
 
 	if tp == reflect.TypeOf(time.Duration(0)) {
-		res, _ = time.ParseDuration(val)
+		res, err = time.ParseDuration(val)
 	}
 
-	if tp == reflect.TypeOf(time.Time{}) {
-		for _, layout := range timeLayouts {
-			t, err := time.Parse(layout, val)
-			if err == nil && !t.IsZero() {
-				res = t
-				break
-			}
-		}
+	if tp == fileModeType {
+		var n uint64
+		n, err = parseOctal(val)
+		res = os.FileMode(n)
 	}
 
-	if res == nil {
-		fmt.Println("nil")
+	// *url.URL is handled by the pointer case
+	if tp == urlType.Elem() {
+		var u *url.URL
+		if u, err = url.Parse(val); err == nil {
+			res = *u
+		}
 	}
 
-	if reflect.TypeOf(res) != reflect.TypeFor[T]() {
-		return reflect.New(tp).Elem().Interface().(T)
+	if err != nil {
+		return nil, err
 	}
 
-	return reflect.ValueOf(res).Interface().(T)
-}
+	if reflect.TypeOf(res) != tp {
+		return nil, fmt.Errorf("type %s is not supported", tp)
+	}
 
-func parseInt64(val string) int64 {
-	n, _ := strconv.ParseInt(val, 10, 64)
-	return n
+	return res, nil
 }
 
 func MakeKeyProviderPrefix(prefix string) func(name string) string {
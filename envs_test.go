@@ -1,13 +1,18 @@
 package envs_test
 
 import (
+	"errors"
+	"net"
+	"net/url"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/OZahed/envs"
+	"github.com/OZahed/envs/envtest"
 )
 
 // Since the function is a generic and depends on the input datatype common table tests do not work as intended
@@ -32,14 +37,11 @@ func TestGetEnv(t *testing.T) {
 		"TEST_BOOL":       "t",
 	}
 
-	for k, v := range testEnvs {
-		_ = os.Setenv(k, v)
-	}
+	envtest.Set(t, testEnvs)
 	date, _ := time.Parse(time.DateOnly, timeStr)
 	strings := []string{"item1", "item2", "item3"}
 	keyProvider := envs.MakeKeyProviderPrefix(appName)
 
-	t.Parallel()
 	t.Run("Test Generic For Port", func(t *testing.T) {
 		if got := envs.Get[int](keyProvider("PORT")); !reflect.DeepEqual(got, 3000) {
 			t.Errorf("GetEnv() = %v, want %v", got, 3000)
@@ -112,19 +114,600 @@ func TestGetEnv(t *testing.T) {
 	t.Run("Test Generic for wring value", func(t *testing.T) {
 		const key = "test"
 
-		_ = os.Setenv(key, "hello world")
+		t.Setenv(key, "hello world")
 		if got := envs.Get[bool](key); !reflect.DeepEqual(got, false) {
 			t.Errorf("GetEnv() = %v, want %v", got, false)
 		}
 
-		_ = os.Setenv(key, "1.234")
+		t.Setenv(key, "1.234")
 		if got := envs.Get[int32](keyProvider(key)); !reflect.DeepEqual(got, int32(0)) {
 			t.Errorf("GetEnv() = %v, want %v", got, 0)
 		}
 
-		_ = os.Setenv(key, "2024-04-38 25:12:28+03:30") // wrong time
+		t.Setenv(key, "2024-04-38 25:12:28+03:30") // wrong time
 		if got := envs.Get[time.Time](keyProvider(key)); !reflect.DeepEqual(got, time.Time{}) {
 			t.Errorf("GetEnv() = %v, want %v", got, time.Time{})
 		}
 	})
 }
+
+func TestMustGet(t *testing.T) {
+	t.Setenv("MUST_GET_PORT", "8080")
+	t.Setenv("MUST_GET_BAD_PORT", "eighty")
+
+	if got := envs.MustGet[int]("MUST_GET_PORT"); got != 8080 {
+		t.Errorf("MustGet() = %v, want %v", got, 8080)
+	}
+
+	for _, key := range []string{"MUST_GET_BAD_PORT", "MUST_GET_UNSET"} {
+		t.Run(key, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("MustGet(%s) did not panic", key)
+				}
+			}()
+
+			envs.MustGet[int](key)
+		})
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	type Config struct {
+		Port int `env:"PORT,default=80"`
+	}
+
+	t.Setenv("MUST_PARSE_BAD_PORT", "eighty")
+
+	if got := envs.MustParse[Config]("MUST_PARSE"); got.Port != 80 {
+		t.Errorf("MustParse() = %v, want %v", got.Port, 80)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustParse() did not panic")
+		}
+	}()
+
+	envs.MustParse[Config]("MUST_PARSE_BAD")
+}
+
+func TestGetErr(t *testing.T) {
+	t.Setenv("GET_ERR_ZERO", "0")
+	t.Setenv("GET_ERR_BAD", "zero")
+
+	if got, err := envs.GetErr[int]("GET_ERR_ZERO"); got != 0 || err != nil {
+		t.Errorf("GetErr() = %v, %v want %v, %v", got, err, 0, nil)
+	}
+
+	if _, err := envs.GetErr[int]("GET_ERR_UNSET"); !errors.Is(err, envs.ErrNotSet) {
+		t.Errorf("GetErr() error = %v, want %v", err, envs.ErrNotSet)
+	}
+
+	_, err := envs.GetErr[int]("GET_ERR_BAD")
+	if err == nil || errors.Is(err, envs.ErrNotSet) {
+		t.Errorf("GetErr() error = %v, want parse error", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	t.Setenv("LOOKUP_DEBUG", "false")
+	t.Setenv("LOOKUP_PORT", "0")
+	t.Setenv("LOOKUP_BAD_PORT", "zero")
+
+	if got, ok := envs.Lookup[bool]("LOOKUP_DEBUG"); got || !ok {
+		t.Errorf("Lookup() = %v, %v want %v, %v", got, ok, false, true)
+	}
+
+	if got, ok := envs.Lookup[int]("LOOKUP_PORT"); got != 0 || !ok {
+		t.Errorf("Lookup() = %v, %v want %v, %v", got, ok, 0, true)
+	}
+
+	if got, ok := envs.Lookup[int]("LOOKUP_UNSET"); got != 0 || ok {
+		t.Errorf("Lookup() = %v, %v want %v, %v", got, ok, 0, false)
+	}
+
+	if _, ok := envs.Lookup[int]("LOOKUP_BAD_PORT"); ok {
+		t.Errorf("Lookup() ok = %v, want %v", ok, false)
+	}
+}
+
+func TestGetOr(t *testing.T) {
+	t.Setenv("GET_OR_RETRIES", "0")
+	t.Setenv("GET_OR_VERBOSE", "false")
+
+	if got := envs.GetOr("GET_OR_RETRIES", 3); got != 0 {
+		t.Errorf("GetOr() = %v, want %v", got, 0)
+	}
+
+	if got := envs.GetDefault("GET_OR_VERBOSE", true); got {
+		t.Errorf("GetDefault() = %v, want %v", got, false)
+	}
+
+	if got := envs.GetOr("GET_OR_UNSET", 3); got != 3 {
+		t.Errorf("GetOr() = %v, want %v", got, 3)
+	}
+}
+
+func TestGetIntegerKinds(t *testing.T) {
+	t.Setenv("INT_KINDS_SMALL", "120")
+	t.Setenv("INT_KINDS_BIG", "70000")
+
+	if got := envs.Get[uint16]("INT_KINDS_SMALL"); got != 120 {
+		t.Errorf("Get() = %v, want %v", got, 120)
+	}
+
+	if got := envs.Get[uint32]("INT_KINDS_BIG"); got != 70000 {
+		t.Errorf("Get() = %v, want %v", got, 70000)
+	}
+
+	if got := envs.Get[uint64]("INT_KINDS_BIG"); got != 70000 {
+		t.Errorf("Get() = %v, want %v", got, 70000)
+	}
+
+	if got := envs.Get[int8]("INT_KINDS_SMALL"); got != 120 {
+		t.Errorf("Get() = %v, want %v", got, 120)
+	}
+
+	if got := envs.Get[int16]("INT_KINDS_BIG"); got != 0 {
+		t.Errorf("Get() = %v, want %v for an overflowing value", got, 0)
+	}
+
+	if _, err := envs.GetErr[uint8]("INT_KINDS_BIG"); err == nil {
+		t.Errorf("GetErr() expected overflow error")
+	}
+}
+
+func TestGetComplex(t *testing.T) {
+	t.Setenv("COMPLEX_VALUE", "1+2i")
+	t.Setenv("COMPLEX_LIST", "(1+2i),3-4.5i")
+	t.Setenv("COMPLEX_BAD", "1+i2")
+
+	if got := envs.Get[complex128]("COMPLEX_VALUE"); got != complex(1, 2) {
+		t.Errorf("Get() = %v, want %v", got, complex(1, 2))
+	}
+
+	if got := envs.Get[complex64]("COMPLEX_VALUE"); got != complex64(complex(1, 2)) {
+		t.Errorf("Get() = %v, want %v", got, complex(1, 2))
+	}
+
+	if _, err := envs.GetErr[complex128]("COMPLEX_BAD"); err == nil {
+		t.Errorf("GetErr() expected error for an invalid complex number")
+	}
+
+	cfg := struct {
+		Value complex64    `env:"VALUE"`
+		List  []complex128 `env:"LIST"`
+	}{}
+	if err := envs.NewParser(nil, nil).ParseStruct(&cfg, "COMPLEX"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	want := []complex128{complex(1, 2), complex(3, -4.5)}
+	if cfg.Value != complex(1, 2) || !reflect.DeepEqual(cfg.List, want) {
+		t.Errorf("ParseStruct() = %v, %v want %v, %v", cfg.Value, cfg.List, complex(1, 2), want)
+	}
+}
+
+func TestGetFileMode(t *testing.T) {
+	t.Setenv("FILE_MODE_PERM", "0644")
+	t.Setenv("FILE_MODE_PREFIXED", "0o750")
+	t.Setenv("FILE_MODE_UMASK", "022")
+	t.Setenv("FILE_MODE_BAD", "0698")
+
+	if got := envs.Get[os.FileMode]("FILE_MODE_PERM"); got != 0o644 {
+		t.Errorf("Get() = %v, want %v", got, os.FileMode(0o644))
+	}
+
+	if got := envs.Get[os.FileMode]("FILE_MODE_PREFIXED"); got != 0o750 {
+		t.Errorf("Get() = %v, want %v", got, os.FileMode(0o750))
+	}
+
+	if _, err := envs.GetErr[os.FileMode]("FILE_MODE_BAD"); err == nil {
+		t.Errorf("GetErr() expected error for a value that is not octal")
+	}
+
+	cfg := struct {
+		Perm  os.FileMode `env:"PERM"`
+		Umask int         `env:"UMASK,octal"`
+	}{}
+	if err := envs.NewParser(nil, nil).ParseStruct(&cfg, "FILE_MODE"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if cfg.Perm != 0o644 || cfg.Umask != 0o22 {
+		t.Errorf("ParseStruct() = %o, %o want %o, %o", cfg.Perm, cfg.Umask, 0o644, 0o22)
+	}
+
+	values, err := envs.ToMap(cfg, "FILE_MODE")
+	if err != nil {
+		t.Fatalf("ToMap() error = %v", err)
+	}
+
+	if values["FILE_MODE_PERM"] != "0644" || values["FILE_MODE_UMASK"] != "022" {
+		t.Errorf("ToMap() = %v, want octal PERM and UMASK values", values)
+	}
+}
+
+func TestGetSlices(t *testing.T) {
+	t.Setenv("SLICES_INT64", "1, 2, 3")
+	t.Setenv("SLICES_FLOAT64", "1.5;2.5")
+	t.Setenv("SLICES_DURATION", "1s,2m")
+	t.Setenv("SLICES_URL", "https://a.com,https://b.com")
+	t.Setenv("SLICES_TIME", "2024-01-01,2024-01-02")
+
+	if got, want := envs.Get[[]int64]("SLICES_INT64"), []int64{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %v, want %v", got, want)
+	}
+
+	if got, want := envs.Get[[]float64]("SLICES_FLOAT64"), []float64{1.5, 2.5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %v, want %v", got, want)
+	}
+
+	want := []time.Duration{time.Second, 2 * time.Minute}
+	if got := envs.Get[[]time.Duration]("SLICES_DURATION"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %v, want %v", got, want)
+	}
+
+	urls := envs.Get[[]*url.URL]("SLICES_URL")
+	if len(urls) != 2 || urls[0].Host != "a.com" || urls[1].Host != "b.com" {
+		t.Errorf("Get() = %v, want hosts a.com and b.com", urls)
+	}
+
+	first, _ := time.Parse(time.DateOnly, "2024-01-01")
+	second, _ := time.Parse(time.DateOnly, "2024-01-02")
+	if got := envs.Get[[]time.Time]("SLICES_TIME"); !reflect.DeepEqual(got, []time.Time{first, second}) {
+		t.Errorf("Get() = %v, want %v", got, []time.Time{first, second})
+	}
+
+	if _, err := envs.GetErr[[]int64]("SLICES_FLOAT64"); err == nil {
+		t.Errorf("GetErr() expected error for invalid element")
+	}
+
+	t.Setenv("SLICES_SINGLE", "only")
+	t.Setenv("SLICES_NEGATIVE", "-5")
+	t.Setenv("SLICES_NEGATIVES", "-5,3,-1")
+	t.Setenv("SLICES_HOST", "api-1.local")
+	t.Setenv("SLICES_DATE", "2024-01-01")
+
+	if got, want := envs.Get[[]string]("SLICES_SINGLE"), []string{"only"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %v, want %v", got, want)
+	}
+
+	if got, err := envs.GetErr[[]int]("SLICES_NEGATIVE"); err != nil || !reflect.DeepEqual(got, []int{-5}) {
+		t.Errorf("GetErr() = %v, %v want %v", got, err, []int{-5})
+	}
+
+	if got, want := envs.Get[[]int]("SLICES_NEGATIVES"), []int{-5, 3, -1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %v, want %v", got, want)
+	}
+
+	if got, want := envs.Get[[]string]("SLICES_HOST"), []string{"api-1.local"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %v, want %v", got, want)
+	}
+
+	if got, err := envs.GetErr[[]time.Time]("SLICES_DATE"); err != nil || !reflect.DeepEqual(got, []time.Time{first}) {
+		t.Errorf("GetErr() = %v, %v want %v", got, err, []time.Time{first})
+	}
+}
+
+func TestGetMaps(t *testing.T) {
+	t.Setenv("MAPS_STRINGS", "host:localhost,scheme:https")
+	t.Setenv("MAPS_INTS", "a:1;b:2")
+	t.Setenv("MAPS_BAD", "a1,b2")
+	t.Setenv("MAPS_DUPLICATE", "a:1,b:2,a:3")
+	t.Setenv("MAPS_LISTS", "grp1:a|b|c,grp2:d|e,grp3:")
+	t.Setenv("MAPS_INT_LISTS", "a:1|2;b:3")
+	t.Setenv("MAPS_DURATION_KEYS", "1s:fast,1m:slow")
+	t.Setenv("MAPS_TEXT_KEYS", "a:1,b:2")
+	t.Setenv("MAPS_URL_VALUES", "api:https://api.local:8443")
+	t.Setenv("MAPS_BAD_KEY", "1s:fast,soon:slow")
+
+	want := map[string]string{"host": "localhost", "scheme": "https"}
+	if got := envs.Get[map[string]string]("MAPS_STRINGS"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %v, want %v", got, want)
+	}
+
+	if got := envs.Get[map[string]int]("MAPS_INTS"); !reflect.DeepEqual(got, map[string]int{"a": 1, "b": 2}) {
+		t.Errorf("Get() = %v, want %v", got, map[string]int{"a": 1, "b": 2})
+	}
+
+	if _, err := envs.GetErr[map[string]int]("MAPS_BAD"); err == nil {
+		t.Errorf("GetErr() expected error for malformed map")
+	}
+
+	if _, err := envs.GetErr[map[string]int]("MAPS_DUPLICATE"); err == nil || !strings.Contains(err.Error(), `"a"`) {
+		t.Errorf("GetErr() error = %v, want an error naming the duplicate key", err)
+	}
+
+	wantLists := map[string][]string{"grp1": {"a", "b", "c"}, "grp2": {"d", "e"}, "grp3": {}}
+	if got := envs.Get[map[string][]string]("MAPS_LISTS"); !reflect.DeepEqual(got, wantLists) {
+		t.Errorf("Get() = %v, want %v", got, wantLists)
+	}
+
+	wantIntLists := map[string][]int{"a": {1, 2}, "b": {3}}
+	if got := envs.Get[map[string][]int]("MAPS_INT_LISTS"); !reflect.DeepEqual(got, wantIntLists) {
+		t.Errorf("Get() = %v, want %v", got, wantIntLists)
+	}
+
+	wantDurations := map[time.Duration]string{time.Second: "fast", time.Minute: "slow"}
+	if got := envs.Get[map[time.Duration]string]("MAPS_DURATION_KEYS"); !reflect.DeepEqual(got, wantDurations) {
+		t.Errorf("Get() = %v, want %v", got, wantDurations)
+	}
+
+	wantTexts := map[upperText]int{"A": 1, "B": 2}
+	if got := envs.Get[map[upperText]int]("MAPS_TEXT_KEYS"); !reflect.DeepEqual(got, wantTexts) {
+		t.Errorf("Get() = %v, want %v", got, wantTexts)
+	}
+
+	wantURLs := map[string]string{"api": "https://api.local:8443"}
+	if got := envs.Get[map[string]string]("MAPS_URL_VALUES"); !reflect.DeepEqual(got, wantURLs) {
+		t.Errorf("Get() = %v, want %v", got, wantURLs)
+	}
+
+	_, err := envs.GetErr[map[time.Duration]string]("MAPS_BAD_KEY")
+	if err == nil || !strings.Contains(err.Error(), `map key "soon"`) {
+		t.Errorf("GetErr() error = %v, want an error naming the bad key", err)
+	}
+}
+
+func TestGetPointers(t *testing.T) {
+	t.Setenv("POINTERS_PORT", "0")
+	t.Setenv("POINTERS_DEBUG", "true")
+
+	if got := envs.Get[*int]("POINTERS_PORT"); got == nil || *got != 0 {
+		t.Errorf("Get() = %v, want pointer to %v", got, 0)
+	}
+
+	if got := envs.Get[*bool]("POINTERS_DEBUG"); got == nil || !*got {
+		t.Errorf("Get() = %v, want pointer to %v", got, true)
+	}
+
+	if got := envs.Get[*time.Duration]("POINTERS_UNSET"); got != nil {
+		t.Errorf("Get() = %v, want %v", got, nil)
+	}
+
+	if got := envs.Get[*int]("POINTERS_DEBUG"); got != nil {
+		t.Errorf("Get() = %v, want %v for an invalid value", got, nil)
+	}
+}
+
+func TestGetURL(t *testing.T) {
+	const dsn = "postgres://user@localhost:5432/db?sslmode=disable"
+	t.Setenv("URL_DATABASE_URL", dsn)
+	t.Setenv("URL_BAD", "://bad")
+
+	if got := envs.Get[*url.URL]("URL_DATABASE_URL"); got == nil || got.String() != dsn {
+		t.Errorf("Get() = %v, want %v", got, dsn)
+	}
+
+	if got := envs.Get[url.URL]("URL_DATABASE_URL"); got.Host != "localhost:5432" {
+		t.Errorf("Get() = %v, want host %v", got.Host, "localhost:5432")
+	}
+
+	if got := envs.Get[*url.URL]("URL_UNSET"); got != nil {
+		t.Errorf("Get() = %v, want %v", got, nil)
+	}
+
+	if _, err := envs.GetErr[*url.URL]("URL_BAD"); err == nil {
+		t.Errorf("GetErr() expected error for invalid url")
+	}
+}
+
+type upperText string
+
+func (u *upperText) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return errors.New("empty text")
+	}
+
+	*u = upperText(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestGetURLs(t *testing.T) {
+	t.Setenv("URLS_UPSTREAMS", "https://a-1.local,https://b-2.local:8080/api")
+	t.Setenv("URLS_SINGLE", "https://my-host.local")
+	t.Setenv("URLS_NO_SCHEME", "https://a.local,b.local")
+	t.Setenv("URLS_BAD", "https://a.local,:bad")
+
+	got := envs.Get[[]*url.URL]("URLS_UPSTREAMS")
+	if len(got) != 2 || got[0].Host != "a-1.local" || got[1].Host != "b-2.local:8080" || got[1].Path != "/api" {
+		t.Errorf("Get() = %v, want the two upstreams", got)
+	}
+
+	if got := envs.Get[[]url.URL]("URLS_SINGLE"); len(got) != 1 || got[0].Host != "my-host.local" {
+		t.Errorf("Get() = %v, want a single url", got)
+	}
+
+	if _, err := envs.GetErr[[]*url.URL]("URLS_NO_SCHEME"); err == nil || !strings.Contains(err.Error(), "url 1") {
+		t.Errorf("GetErr() error = %v, want an error for the second url", err)
+	}
+
+	cfg := struct {
+		Upstreams []*url.URL `env:"UPSTREAMS"`
+		Bad       []*url.URL `env:"BAD"`
+	}{}
+	err := envs.NewParser(nil, nil).ParseStruct(&cfg, "URLS")
+	if err == nil || !strings.Contains(err.Error(), "URLS_BAD") {
+		t.Fatalf("ParseStruct() error = %v, want an error naming URLS_BAD", err)
+	}
+
+	if len(cfg.Upstreams) != 2 || cfg.Upstreams[0].String() != "https://a-1.local" {
+		t.Errorf("ParseStruct() Upstreams = %v, want the two upstreams", cfg.Upstreams)
+	}
+}
+
+func TestGetTextUnmarshaler(t *testing.T) {
+	t.Setenv("TEXT_UNMARSHALER_NAME", "envs")
+	t.Setenv("TEXT_UNMARSHALER_IP", "10.0.0.1")
+
+	if got := envs.Get[upperText]("TEXT_UNMARSHALER_NAME"); got != "ENVS" {
+		t.Errorf("Get() = %v, want %v", got, "ENVS")
+	}
+
+	if got := envs.Get[*upperText]("TEXT_UNMARSHALER_NAME"); got == nil || *got != "ENVS" {
+		t.Errorf("Get() = %v, want pointer to %v", got, "ENVS")
+	}
+
+	if got := envs.Get[net.IP]("TEXT_UNMARSHALER_IP"); !got.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("Get() = %v, want %v", got, "10.0.0.1")
+	}
+
+	cfg := struct {
+		Name upperText `env:"NAME"`
+	}{}
+	if err := envs.NewParser(nil, nil).ParseStruct(&cfg, "TEXT_UNMARSHALER"); err != nil || cfg.Name != "ENVS" {
+		t.Errorf("ParseStruct() = %v, %v want %v", cfg.Name, err, "ENVS")
+	}
+}
+
+func TestNewGetter(t *testing.T) {
+	values := map[string]string{
+		"SVC_NAME":    "getter",
+		"SVC_PORT":    "8080",
+		"SVC_TIMEOUT": "5s",
+		"SVC_HOSTS":   "a,b",
+		"SVC_DEBUG":   "true",
+		"SVC_BAD":     "not a number",
+	}
+
+	g := envs.NewGetter("SVC", func(key, def string) string {
+		if val, ok := values[key]; ok {
+			return val
+		}
+
+		return def
+	})
+
+	if got := g.GetString("NAME", ""); got != "getter" {
+		t.Errorf("GetString() = %v, want %v", got, "getter")
+	}
+
+	if got := g.GetInt("PORT", 0); got != 8080 {
+		t.Errorf("GetInt() = %v, want %v", got, 8080)
+	}
+
+	if got := g.GetDuration("TIMEOUT", 0); got != 5*time.Second {
+		t.Errorf("GetDuration() = %v, want %v", got, 5*time.Second)
+	}
+
+	if got := g.GetStringSlice("HOSTS"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("GetStringSlice() = %v, want %v", got, []string{"a", "b"})
+	}
+
+	if got := g.GetBool("DEBUG"); !got {
+		t.Errorf("GetBool() = %v, want %v", got, true)
+	}
+
+	if got := g.GetInt("BAD", 10); got != 10 {
+		t.Errorf("GetInt() = %v, want %v", got, 10)
+	}
+
+	if got := g.GetInt64("MISSING", 42); got != 42 {
+		t.Errorf("GetInt64() = %v, want %v", got, 42)
+	}
+}
+
+func TestGetter_extendedTypes(t *testing.T) {
+	values := map[string]string{
+		"SVC_PORT":     "8080",
+		"SVC_URL":      "https://example.com",
+		"SVC_IDS":      "1,2,3",
+		"SVC_BACKOFF":  "1s,2s",
+		"SVC_LABELS":   "team:core,tier:1",
+		"SVC_CERT_PEM": "-----BEGIN-----",
+	}
+
+	g := envs.NewGetter("SVC", func(key, def string) string {
+		if val, ok := values[key]; ok {
+			return val
+		}
+
+		return def
+	})
+
+	if got := g.GetUint("PORT", 0); got != 8080 {
+		t.Errorf("GetUint() = %v, want %v", got, 8080)
+	}
+
+	if got := g.GetUint64("MISSING", 7); got != 7 {
+		t.Errorf("GetUint64() = %v, want %v", got, 7)
+	}
+
+	if got := g.GetURL("URL"); got == nil || got.Host != "example.com" {
+		t.Errorf("GetURL() = %v, want host %v", got, "example.com")
+	}
+
+	if got := g.GetURL("MISSING"); got != nil {
+		t.Errorf("GetURL() = %v, want %v", got, nil)
+	}
+
+	if got := g.GetIntSlice("IDS"); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("GetIntSlice() = %v, want %v", got, []int{1, 2, 3})
+	}
+
+	want := []time.Duration{time.Second, 2 * time.Second}
+	if got := g.GetDurationSlice("BACKOFF"); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetDurationSlice() = %v, want %v", got, want)
+	}
+
+	labels := map[string]string{"team": "core", "tier": "1"}
+	if got := g.GetStringMap("LABELS"); !reflect.DeepEqual(got, labels) {
+		t.Errorf("GetStringMap() = %v, want %v", got, labels)
+	}
+
+	if got := g.GetBytes("CERT_PEM"); string(got) != "-----BEGIN-----" {
+		t.Errorf("GetBytes() = %s, want %v", got, "-----BEGIN-----")
+	}
+}
+
+func TestGetter_errorVariants(t *testing.T) {
+	values := map[string]string{
+		"SVC_PORT":    "8080",
+		"SVC_BAD":     "eighty",
+		"SVC_TIMEOUT": "forever",
+	}
+
+	g := envs.NewGetter("SVC", func(key, def string) string {
+		if val, ok := values[key]; ok {
+			return val
+		}
+
+		return def
+	})
+
+	if got, err := g.GetIntE("PORT", 0); got != 8080 || err != nil {
+		t.Errorf("GetIntE() = %v, %v want %v, %v", got, err, 8080, nil)
+	}
+
+	if got, err := g.GetIntE("MISSING", 9); got != 9 || err != nil {
+		t.Errorf("GetIntE() = %v, %v want %v, %v", got, err, 9, nil)
+	}
+
+	if _, err := g.GetIntE("BAD", 9); err == nil || !strings.Contains(err.Error(), "SVC_BAD") {
+		t.Errorf("GetIntE() error = %v, want error mentioning %v", err, "SVC_BAD")
+	}
+
+	if _, err := g.GetDurationE("TIMEOUT", time.Second); err == nil {
+		t.Errorf("GetDurationE() expected error for malformed duration")
+	}
+}
+
+func TestNewMapGetter(t *testing.T) {
+	values := map[string]string{"PORT": "8080", "HOSTS": "a;b"}
+	g := envs.NewMapGetter(values)
+	values["PORT"] = "9090"
+
+	if got := g.GetInt("PORT", 0); got != 8080 {
+		t.Errorf("GetInt() = %v, want %v", got, 8080)
+	}
+
+	if got := g.GetStringSlice("HOSTS"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("GetStringSlice() = %v, want %v", got, []string{"a", "b"})
+	}
+
+	if got := g.GetString("MISSING", "def"); got != "def" {
+		t.Errorf("GetString() = %v, want %v", got, "def")
+	}
+}
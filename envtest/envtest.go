@@ -0,0 +1,32 @@
+// Package envtest sets environment variables in tests and restores their previous values once the test finishes,
+// so tests do not need manual os.Setenv loops and do not leak variables into each other.
+// like testing.T.Setenv it can not be used in parallel tests
+package envtest
+
+import (
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+// Set sets every variable in values for the duration of the test
+func Set(t testing.TB, values map[string]string) {
+	t.Helper()
+
+	for k, v := range values {
+		t.Setenv(k, v)
+	}
+}
+
+// SetStruct sets the variables ParseStruct reads cfg from under prefix for the duration of the test,
+// see envs.ToMap
+func SetStruct(t testing.TB, cfg interface{}, prefix string) {
+	t.Helper()
+
+	values, err := envs.ToMap(cfg, prefix)
+	if err != nil {
+		t.Fatalf("envtest: %v", err)
+	}
+
+	Set(t, values)
+}
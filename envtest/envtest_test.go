@@ -0,0 +1,53 @@
+package envtest_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/OZahed/envs"
+	"github.com/OZahed/envs/envtest"
+)
+
+func TestSet(t *testing.T) {
+	t.Setenv("ENVTEST_NAME", "before")
+
+	t.Run("set", func(t *testing.T) {
+		envtest.Set(t, map[string]string{"ENVTEST_NAME": "svc", "ENVTEST_PORT": "8080"})
+
+		if got := os.Getenv("ENVTEST_NAME"); got != "svc" {
+			t.Errorf("ENVTEST_NAME = %q, want svc", got)
+		}
+	})
+
+	if got := os.Getenv("ENVTEST_NAME"); got != "before" {
+		t.Errorf("ENVTEST_NAME after the test = %q, want before", got)
+	}
+
+	if _, ok := os.LookupEnv("ENVTEST_PORT"); ok {
+		t.Error("ENVTEST_PORT is still set after the test")
+	}
+}
+
+func TestSetStruct(t *testing.T) {
+	type Config struct {
+		Name  string   `env:"NAME"`
+		Port  int      `env:"PORT"`
+		Hosts []string `env:"HOSTS"`
+	}
+
+	cfg := Config{Name: "svc", Port: 8080, Hosts: []string{"a", "b"}}
+
+	t.Run("set", func(t *testing.T) {
+		envtest.SetStruct(t, cfg, "ENVTEST")
+
+		var got Config
+		if err := envs.NewParser(nil, nil).ParseStruct(&got, "ENVTEST"); err != nil || !reflect.DeepEqual(got, cfg) {
+			t.Errorf("ParseStruct() = %+v, %v, want %+v", got, err, cfg)
+		}
+	})
+
+	if _, ok := os.LookupEnv("ENVTEST_PORT"); ok {
+		t.Error("ENVTEST_PORT is still set after the test")
+	}
+}
@@ -0,0 +1,70 @@
+package envs
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// EtcdSource reads values from etcd v3 through its JSON gateway, keys are looked up under Prefix
+// so a field with the key PORT is read from /config/app/PORT with the prefix /config/app/
+type EtcdSource struct {
+	// Endpoint is the base URL of an etcd member like http://127.0.0.1:2379
+	Endpoint string
+	// Prefix is prepended to every key
+	Prefix string
+	// Header is sent with every request, like an Authorization token
+	Header http.Header
+	// Client sends the requests, http.DefaultClient is used when it is nil
+	Client *http.Client
+}
+
+// NewEtcdSource creates an EtcdSource reading keys under prefix from the etcd member at endpoint
+func NewEtcdSource(endpoint, prefix string) *EtcdSource {
+	return &EtcdSource{Endpoint: endpoint, Prefix: prefix}
+}
+
+type etcdRangeResponse struct {
+	Kvs []struct {
+		Value string `json:"value"`
+	} `json:"kvs"`
+}
+
+// Lookup reads Prefix+key with a range request
+func (s *EtcdSource) Lookup(ctx context.Context, key string) (string, bool, error) {
+	body, err := json.Marshal(map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(s.Prefix + key))})
+	if err != nil {
+		return "", false, err
+	}
+
+	header := http.Header{"Content-Type": {"application/json"}}
+	for k, v := range s.Header {
+		header[k] = v
+	}
+
+	url := strings.TrimSuffix(s.Endpoint, "/") + "/v3/kv/range"
+	data, _, found, err := fetch(ctx, s.Client, http.MethodPost, url, header, bytes.NewReader(body))
+	if err != nil || !found {
+		return "", false, err
+	}
+
+	var res etcdRangeResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return "", false, fmt.Errorf("etcd: invalid range response: %w", err)
+	}
+
+	if len(res.Kvs) == 0 {
+		return "", false, nil
+	}
+
+	val, err := base64.StdEncoding.DecodeString(res.Kvs[0].Value)
+	if err != nil {
+		return "", false, fmt.Errorf("etcd: invalid value of %s: %w", key, err)
+	}
+
+	return string(val), true, nil
+}
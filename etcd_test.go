@@ -0,0 +1,60 @@
+package envs_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestEtcdSource(t *testing.T) {
+	store := map[string]string{"/config/app/APP_PORT": "9090", "/config/app/APP_NAME": ""}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v3/kv/range" || req.Header.Get("Authorization") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var body struct{ Key string }
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		key, _ := base64.StdEncoding.DecodeString(body.Key)
+		res := map[string][]map[string]string{}
+		if val, ok := store[string(key)]; ok {
+			res["kvs"] = []map[string]string{{"value": base64.StdEncoding.EncodeToString([]byte(val))}}
+		}
+
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer server.Close()
+
+	type Config struct {
+		Name string `env:"NAME,default=svc"`
+		Port int    `env:"PORT"`
+		Host string `env:"HOST,default=localhost"`
+	}
+
+	source := envs.NewEtcdSource(server.URL, "/config/app/")
+	source.Header = http.Header{"Authorization": {"token"}}
+
+	var got Config
+	if err := envs.NewParserOpts(envs.WithSource(source)).ParseStruct(&got, "APP"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if want := (Config{Port: 9090, Host: "localhost"}); got != want {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+
+	source.Header = nil
+	if _, _, err := source.Lookup(context.Background(), "APP_PORT"); err == nil {
+		t.Error("Lookup() expected an error for an unauthorized request")
+	}
+}
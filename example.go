@@ -0,0 +1,76 @@
+package envs
+
+import (
+	"bytes"
+	"fmt"
+	r "reflect"
+	"strconv"
+	"strings"
+)
+
+// GenerateExample returns a .env.example template for cfg, every key the parser reads is listed with its
+// type, default value, description and whether it is required. fields without a default get their example value.
+func GenerateExample(cfg interface{}, prefix string) ([]byte, error) {
+	return NewParser(nil, nil).GenerateExample(cfg, prefix)
+}
+
+// GenerateExample returns a .env.example template for cfg using the parser key function and tag name,
+// an empty prefix falls back to the one configured with WithPrefix.
+func (m *Parser) GenerateExample(cfg interface{}, prefix string) ([]byte, error) {
+	if prefix == "" {
+		prefix = m.prefix
+	}
+
+	buf := &bytes.Buffer{}
+	err := m.walk(r.ValueOf(cfg), prefix, "", func(f field) error {
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+
+		notes := []string{f.Type.String()}
+		if f.Tag.octal {
+			notes = append(notes, "base 8")
+		}
+
+		if m.required(f.Tag) {
+			notes = append(notes, "required")
+		}
+
+		if f.Tag.secret {
+			notes = append(notes, "secret")
+		}
+
+		if f.Tag.def != "" {
+			notes = append(notes, "default: "+f.Tag.def)
+		}
+
+		fmt.Fprintf(buf, "# %s (%s)\n", f.Path, strings.Join(notes, ", "))
+		if f.Tag.desc != "" {
+			fmt.Fprintf(buf, "# %s\n", f.Tag.desc)
+		}
+
+		// examples are only a hint for fields without a default
+		val := f.Tag.def
+		if val == "" {
+			val = f.Tag.example
+		}
+
+		fmt.Fprintf(buf, "%s=%s\n", f.Key, quoteEnvValue(val))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// quoteEnvValue double quotes values that a .env reader would otherwise split or cut
+func quoteEnvValue(val string) string {
+	if strings.ContainsAny(val, " \t\n\"'#$\\") {
+		return strconv.Quote(val)
+	}
+
+	return val
+}
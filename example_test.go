@@ -0,0 +1,46 @@
+package envs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func TestGenerateExample(t *testing.T) {
+	type Config struct {
+		Name   string `env:"NAME,default=my service"`
+		Token  string `env:"TOKEN,secret,required,desc='api token, from the dashboard',example='tok_123'"`
+		Server struct {
+			Port    int           `env:"PORT,default=8080"`
+			Timeout time.Duration `env:"TIMEOUT"`
+		} `env:"SERVER"`
+		Umask int `env:"UMASK,octal,default=022"`
+	}
+
+	want := `# Name (string, default: my service)
+APP_NAME="my service"
+
+# Token (string, required, secret)
+# api token, from the dashboard
+APP_TOKEN=tok_123
+
+# Server.Port (int, default: 8080)
+APP_SERVER_PORT=8080
+
+# Server.Timeout (time.Duration)
+APP_SERVER_TIMEOUT=
+
+# Umask (int, base 8, default: 022)
+APP_UMASK=022
+`
+
+	got, err := envs.GenerateExample(Config{}, "APP")
+	if err != nil {
+		t.Fatalf("GenerateExample() error = %v", err)
+	}
+
+	if string(got) != want {
+		t.Errorf("GenerateExample() got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package envs
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// WithExpand substitutes $VAR and ${VAR} references in values with the values of those variables, read from the
+// same source as the fields, before templates and value hooks run. on windows %VAR% references are substituted
+// as well. missing $VAR references become empty like os.ExpandEnv does while missing %VAR% ones are kept as they are
+func WithExpand() Option {
+	return func(p *Parser) {
+		p.expandVars = true
+	}
+}
+
+// expandRefs substitutes the variable references in val when WithExpand is used
+func (m *Parser) expandRefs(st *decodeState, val string) (string, error) {
+	if !m.expandVars || !strings.ContainsAny(val, "$%") {
+		return val, nil
+	}
+
+	var err error
+	lookup := func(name string) (string, bool) {
+		if st.environ != nil {
+			return st.environ.lookup(name)
+		}
+
+		v, found, lookupErr := m.get(st.context(), name)
+		if lookupErr != nil && err == nil {
+			err = lookupErr
+		}
+
+		return v, found
+	}
+
+	val = os.Expand(val, func(name string) string {
+		v, _ := lookup(name)
+		return v
+	})
+
+	if runtime.GOOS == "windows" {
+		val = expandPercent(val, lookup)
+	}
+
+	return val, err
+}
+
+// expandPercent substitutes %VAR% references, references to missing variables are kept
+func expandPercent(val string, lookup func(name string) (string, bool)) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(val, '%')
+		if start < 0 {
+			break
+		}
+
+		end := strings.IndexByte(val[start+1:], '%')
+		if end < 0 {
+			break
+		}
+
+		end += start + 1
+		name := val[start+1 : end]
+		if v, ok := lookup(name); ok && name != "" && !strings.ContainsAny(name, " \t") {
+			b.WriteString(val[:start])
+			b.WriteString(v)
+			val = val[end+1:]
+			continue
+		}
+
+		b.WriteString(val[:end])
+		val = val[end:]
+	}
+
+	b.WriteString(val)
+	return b.String()
+}
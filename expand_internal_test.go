@@ -0,0 +1,37 @@
+package envs
+
+import "testing"
+
+// expandPercent only runs on windows, it is tested directly so every platform covers it
+func TestExpandPercent(t *testing.T) {
+	vars := map[string]string{"USER": "svc", "HOME": `C:\Users\svc`, "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+
+	tests := map[string]string{
+		"%USER%":                 "svc",
+		"%HOME%\\cache":          `C:\Users\svc\cache`,
+		"%USER%%USER%":           "svcsvc",
+		"[%EMPTY%]":              "[]",
+		"%MISSING%":              "%MISSING%",
+		"%MISSING% and %USER%":   "%MISSING% and svc",
+		"100%":                   "100%",
+		"%unterminated":          "%unterminated",
+		"%USER":                  "%USER",
+		"%%":                     "%%",
+		"100%% sure":             "100%% sure",
+		"50% of %USER%":          "50% of svc",
+		"%NOT A VAR% and %USER%": "%NOT A VAR% and svc",
+		"":                       "",
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			if got := expandPercent(input, lookup); got != want {
+				t.Errorf("expandPercent(%q) = %q, want %q", input, got, want)
+			}
+		})
+	}
+}
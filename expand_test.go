@@ -0,0 +1,49 @@
+package envs_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestWithExpand(t *testing.T) {
+	type Config struct {
+		Home  string `env:"HOME_DIR"`
+		Cache string `env:"CACHE"`
+		URL   string `env:"URL"`
+		Price string `env:"PRICE"`
+	}
+
+	values := envs.FromMap(map[string]string{
+		"BASE":            "/srv",
+		"USER":            "svc",
+		"EXPAND_HOME_DIR": "$BASE/home",
+		"EXPAND_CACHE":    "${BASE}/cache/$MISSING",
+		"EXPAND_URL":      "https://%USER%@example.com/%MISSING%",
+		"EXPAND_PRICE":    "100%",
+	})
+
+	var got Config
+	if err := envs.NewParserOpts(envs.WithValueFunc(values), envs.WithExpand()).ParseStruct(&got, "EXPAND"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	want := Config{Home: "/srv/home", Cache: "/srv/cache/", URL: "https://%USER%@example.com/%MISSING%", Price: "100%"}
+	if runtime.GOOS == "windows" {
+		want.URL = "https://svc@example.com/%MISSING%"
+	}
+
+	if got != want {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+
+	environ := []string{"BASE=/opt", "EXPAND_HOME_DIR=$BASE/home"}
+	if err := envs.NewParserOpts(envs.WithExpand()).ParseStructFrom(environ, &got, "EXPAND"); err != nil {
+		t.Fatalf("ParseStructFrom() error = %v", err)
+	}
+
+	if got.Home != "/opt/home" {
+		t.Errorf("ParseStructFrom() Home = %q, want /opt/home", got.Home)
+	}
+}
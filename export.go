@@ -0,0 +1,187 @@
+package envs
+
+import (
+	"bytes"
+	"fmt"
+	r "reflect"
+	"strconv"
+	"strings"
+)
+
+// exportEntry is a single key and the value an exporter writes for it
+type exportEntry struct {
+	Key    string
+	Value  string
+	Secret bool
+}
+
+// exportEntries walks cfg and returns every key with its current value, zero values fall back to the tag default.
+// Redacted values are revealed and treated as secrets.
+func (m *Parser) exportEntries(cfg interface{}, prefix string) ([]exportEntry, error) {
+	if prefix == "" {
+		prefix = m.prefix
+	}
+
+	var entries []exportEntry
+	err := m.walk(r.ValueOf(cfg), prefix, "", func(f field) error {
+		entry := exportEntry{Key: f.Key, Secret: f.Tag.secret, Value: f.Tag.def}
+
+		value := f.Value
+		if rev, ok := value.Interface().(revealer); ok {
+			value = r.ValueOf(rev.reveal())
+			entry.Secret = true
+		}
+
+		if value.IsValid() && !value.IsZero() {
+			entry.Value = m.formatField(value, f.Tag)
+		}
+
+		entries = append(entries, entry)
+		return nil
+	})
+
+	return entries, err
+}
+
+// KubernetesManifests returns a ConfigMap and, when cfg has secret fields, a Secret manifest named name holding
+// every key of cfg, fields tagged as `secret` and Redacted values go to the Secret.
+func KubernetesManifests(cfg interface{}, prefix, name string) ([]byte, error) {
+	return NewParser(nil, nil).KubernetesManifests(cfg, prefix, name)
+}
+
+// KubernetesManifests returns a ConfigMap and, when cfg has secret fields, a Secret manifest named name
+// using the parser key function, an empty prefix falls back to the one configured with WithPrefix.
+func (m *Parser) KubernetesManifests(cfg interface{}, prefix, name string) ([]byte, error) {
+	entries, err := m.exportEntries(cfg, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var plain, secrets []exportEntry
+	for _, entry := range entries {
+		if entry.Secret {
+			secrets = append(secrets, entry)
+			continue
+		}
+
+		plain = append(plain, entry)
+	}
+
+	buf := &bytes.Buffer{}
+	writeManifest(buf, "ConfigMap", name, "data", plain)
+
+	if len(secrets) > 0 {
+		buf.WriteString("---\n")
+		writeManifest(buf, "Secret", name, "stringData", secrets)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeManifest(buf *bytes.Buffer, kind, name, dataKey string, entries []exportEntry) {
+	fmt.Fprintf(buf, "apiVersion: v1\nkind: %s\nmetadata:\n  name: %s\n", kind, strconv.Quote(name))
+	if kind == "Secret" {
+		buf.WriteString("type: Opaque\n")
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintf(buf, "%s: {}\n", dataKey)
+		return
+	}
+
+	fmt.Fprintf(buf, "%s:\n", dataKey)
+	for _, entry := range entries {
+		// double quoted Go strings are valid YAML double quoted scalars
+		fmt.Fprintf(buf, "  %s: %s\n", entry.Key, strconv.Quote(entry.Value))
+	}
+}
+
+// SystemdEnvironmentFile returns cfg as a systemd EnvironmentFile, one KEY=value per line without export keywords
+func SystemdEnvironmentFile(cfg interface{}, prefix string) ([]byte, error) {
+	return NewParser(nil, nil).SystemdEnvironmentFile(cfg, prefix)
+}
+
+// SystemdEnvironmentFile returns cfg as a systemd EnvironmentFile using the parser key function,
+// an empty prefix falls back to the one configured with WithPrefix.
+func (m *Parser) SystemdEnvironmentFile(cfg interface{}, prefix string) ([]byte, error) {
+	entries, err := m.exportEntries(cfg, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	for _, entry := range entries {
+		fmt.Fprintf(buf, "%s=%s\n", entry.Key, quoteSystemd(entry.Value))
+	}
+
+	return buf.Bytes(), nil
+}
+
+// quoteSystemd double quotes values systemd would otherwise trim or cut, inside double quotes systemd only
+// treats backslash as an escape character and keeps newlines as they are.
+func quoteSystemd(val string) string {
+	if !strings.ContainsAny(val, " \t\n\"'\\#;") {
+		return val
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(val) + `"`
+}
+
+// ComposeEnvironment returns the `environment:` block of a docker-compose service for cfg,
+// secrets are written as `${KEY}` placeholders so their values come from the shell running compose.
+func ComposeEnvironment(cfg interface{}, prefix string) ([]byte, error) {
+	return NewParser(nil, nil).ComposeEnvironment(cfg, prefix)
+}
+
+// ComposeEnvironment returns the `environment:` block of a docker-compose service for cfg using the parser
+// key function, an empty prefix falls back to the one configured with WithPrefix.
+func (m *Parser) ComposeEnvironment(cfg interface{}, prefix string) ([]byte, error) {
+	entries, err := m.exportEntries(cfg, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("environment:\n")
+	for _, entry := range entries {
+		fmt.Fprintf(buf, "  %s: %s\n", entry.Key, strconv.Quote(composeValue(entry)))
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ComposeEnvFile returns cfg as the content of a docker-compose `env_file`, secrets are written
+// as `${KEY}` placeholders.
+func ComposeEnvFile(cfg interface{}, prefix string) ([]byte, error) {
+	return NewParser(nil, nil).ComposeEnvFile(cfg, prefix)
+}
+
+// ComposeEnvFile returns cfg as the content of a docker-compose `env_file` using the parser key function,
+// an empty prefix falls back to the one configured with WithPrefix.
+func (m *Parser) ComposeEnvFile(cfg interface{}, prefix string) ([]byte, error) {
+	entries, err := m.exportEntries(cfg, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	for _, entry := range entries {
+		val := composeValue(entry)
+		if !entry.Secret {
+			val = quoteEnvValue(val)
+		}
+
+		fmt.Fprintf(buf, "%s=%s\n", entry.Key, val)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// composeValue escapes `$` so compose does not interpolate values and turns secrets into placeholders
+func composeValue(entry exportEntry) string {
+	if entry.Secret {
+		return "${" + entry.Key + "}"
+	}
+
+	return strings.ReplaceAll(entry.Value, "$", "$$")
+}
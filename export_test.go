@@ -0,0 +1,114 @@
+package envs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+type exportConfig struct {
+	Name     string                `env:"NAME,default=svc"`
+	Token    string                `env:"TOKEN,secret"`
+	Password envs.Redacted[string] `env:"PASSWORD"`
+	Server   struct {
+		Port    int           `env:"PORT,default=8080"`
+		Timeout time.Duration `env:"TIMEOUT"`
+	} `env:"SERVER"`
+}
+
+func newExportConfig() exportConfig {
+	cfg := exportConfig{Token: "tok", Password: envs.NewRedacted("hunter2")}
+	cfg.Server.Timeout = 5 * time.Second
+
+	return cfg
+}
+
+func TestKubernetesManifests(t *testing.T) {
+	want := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: "app"
+data:
+  APP_NAME: "svc"
+  APP_SERVER_PORT: "8080"
+  APP_SERVER_TIMEOUT: "5s"
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: "app"
+type: Opaque
+stringData:
+  APP_TOKEN: "tok"
+  APP_PASSWORD: "hunter2"
+`
+
+	got, err := envs.KubernetesManifests(newExportConfig(), "APP", "app")
+	if err != nil {
+		t.Fatalf("KubernetesManifests() error = %v", err)
+	}
+
+	if string(got) != want {
+		t.Errorf("KubernetesManifests() got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSystemdEnvironmentFile(t *testing.T) {
+	cfg := newExportConfig()
+	cfg.Name = `my "quoted" svc`
+
+	want := `APP_NAME="my \"quoted\" svc"
+APP_TOKEN=tok
+APP_PASSWORD=hunter2
+APP_SERVER_PORT=8080
+APP_SERVER_TIMEOUT=5s
+`
+
+	got, err := envs.SystemdEnvironmentFile(cfg, "APP")
+	if err != nil {
+		t.Fatalf("SystemdEnvironmentFile() error = %v", err)
+	}
+
+	if string(got) != want {
+		t.Errorf("SystemdEnvironmentFile() got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestComposeEnvironment(t *testing.T) {
+	cfg := newExportConfig()
+	cfg.Name = "price $5"
+
+	want := `environment:
+  APP_NAME: "price $$5"
+  APP_TOKEN: "${APP_TOKEN}"
+  APP_PASSWORD: "${APP_PASSWORD}"
+  APP_SERVER_PORT: "8080"
+  APP_SERVER_TIMEOUT: "5s"
+`
+
+	got, err := envs.ComposeEnvironment(cfg, "APP")
+	if err != nil {
+		t.Fatalf("ComposeEnvironment() error = %v", err)
+	}
+
+	if string(got) != want {
+		t.Errorf("ComposeEnvironment() got:\n%s\nwant:\n%s", got, want)
+	}
+
+	wantFile := `APP_NAME="price $$5"
+APP_TOKEN=${APP_TOKEN}
+APP_PASSWORD=${APP_PASSWORD}
+APP_SERVER_PORT=8080
+APP_SERVER_TIMEOUT=5s
+`
+
+	got, err = envs.ComposeEnvFile(cfg, "APP")
+	if err != nil {
+		t.Fatalf("ComposeEnvFile() error = %v", err)
+	}
+
+	if string(got) != wantFile {
+		t.Errorf("ComposeEnvFile() got:\n%s\nwant:\n%s", got, wantFile)
+	}
+}
@@ -0,0 +1,161 @@
+package envs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoHistory is returned by Holder.Rollback when there is no earlier configuration to return to
+var ErrNoHistory = errors.New("no earlier configuration")
+
+// Version is a configuration kept by a Holder and the time it was loaded
+type Version[T any] struct {
+	Config   T
+	LoadedAt time.Time
+}
+
+// Holder keeps the current configuration of type T behind an atomic pointer, so handlers always read a consistent
+// snapshot while it is reloaded instead of racing on struct fields. a Holder is safe for concurrent use
+type Holder[T any] struct {
+	compiled *Compiled[T]
+	prefix   string
+	value    atomic.Pointer[T]
+
+	// reloadMu serializes reloads so subscribers see changes in order
+	reloadMu sync.Mutex
+
+	mu          sync.Mutex
+	subscribers []func(old, new T)
+	// history holds the current and earlier configurations, oldest first, see SetHistory
+	history []Version[T]
+	size    int
+	// rolledBack is the configuration Rollback dropped, reloads keep the rollback while the sources still hold it
+	rolledBack *T
+}
+
+// NewHolder parses T with a parser built from opts and returns a Holder holding the result
+func NewHolder[T any](prefix string, opts ...Option) (*Holder[T], error) {
+	h := &Holder[T]{compiled: Compile[T](opts...), prefix: prefix}
+
+	value, err := h.compiled.Parse(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	h.value.Store(&value)
+	h.history = []Version[T]{{Config: value, LoadedAt: time.Now()}}
+	h.size = 1
+
+	return h, nil
+}
+
+// Load returns the current configuration
+func (h *Holder[T]) Load() T {
+	return *h.value.Load()
+}
+
+// Subscribe registers fn to be called with the old and new configuration after a reload changed it
+func (h *Holder[T]) Subscribe(fn func(old, new T)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.subscribers = append(h.subscribers, fn)
+}
+
+// Reload parses T again and reports whether the result changed, the new configuration is stored and subscribers
+// are called before it returns. the current configuration is kept when parsing fails
+func (h *Holder[T]) Reload(ctx context.Context) (bool, error) {
+	h.reloadMu.Lock()
+	defer h.reloadMu.Unlock()
+
+	next, err := h.compiled.ParseCtx(ctx, h.prefix)
+	if err != nil {
+		return false, err
+	}
+
+	if h.rolledBack != nil && sameValues(*h.rolledBack, next) {
+		return false, nil
+	}
+
+	h.rolledBack = nil
+	old := h.Load()
+	if sameValues(old, next) {
+		return false, nil
+	}
+
+	h.mu.Lock()
+	h.history = append(h.history, Version[T]{Config: next, LoadedAt: time.Now()})
+	if len(h.history) > h.size {
+		h.history = h.history[len(h.history)-h.size:]
+	}
+	h.mu.Unlock()
+
+	h.swap(old, next)
+	return true, nil
+}
+
+// SetHistory makes the Holder keep the last n configurations including the current one for Rollback,
+// only the current one is kept by default
+func (h *Holder[T]) SetHistory(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.size = max(n, 1)
+	if len(h.history) > h.size {
+		h.history = h.history[len(h.history)-h.size:]
+	}
+}
+
+// History returns the kept configurations, oldest first, the last one is the current configuration
+func (h *Holder[T]) History() []Version[T] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history := make([]Version[T], len(h.history))
+	copy(history, h.history)
+
+	return history
+}
+
+// Rollback drops the current configuration and returns to the one before it, subscribers are called like after a
+// reload. reloads, like the ones of a Watcher, keep the rollback until the sources hold other values than the
+// dropped configuration
+func (h *Holder[T]) Rollback() error {
+	h.reloadMu.Lock()
+	defer h.reloadMu.Unlock()
+
+	h.mu.Lock()
+	if len(h.history) < 2 {
+		h.mu.Unlock()
+		return ErrNoHistory
+	}
+
+	h.history = h.history[:len(h.history)-1]
+	prev := h.history[len(h.history)-1].Config
+	h.mu.Unlock()
+
+	// the sources still hold the configuration of the first rollback when there are several in a row
+	cur := h.Load()
+	if h.rolledBack == nil {
+		h.rolledBack = &cur
+	}
+
+	h.swap(cur, prev)
+	return nil
+}
+
+// swap stores next and calls the subscribers
+func (h *Holder[T]) swap(old, next T) {
+	h.value.Store(&next)
+
+	h.mu.Lock()
+	subscribers := h.subscribers
+	h.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(old, next)
+	}
+}
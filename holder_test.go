@@ -0,0 +1,183 @@
+package envs_test
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestHolder(t *testing.T) {
+	type Config struct {
+		Port  int      `env:"PORT"`
+		Hosts []string `env:"HOSTS"`
+	}
+
+	source := &notifyingSource{values: map[string]string{"HOLD_PORT": "8080", "HOLD_HOSTS": "a,b"}}
+	h, err := envs.NewHolder[Config]("HOLD", envs.WithSource(source))
+	if err != nil {
+		t.Fatalf("NewHolder() error = %v", err)
+	}
+
+	var got []int
+	h.Subscribe(func(old, new Config) { got = append(got, old.Port, new.Port) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				if cfg := h.Load(); len(cfg.Hosts) != 2 || (cfg.Port != 8080 && cfg.Port != 9090) {
+					t.Errorf("Load() = %+v, want a complete configuration", cfg)
+					return
+				}
+			}
+		}()
+	}
+
+	source.set("HOLD_PORT", "9090")
+	if changed, err := h.Reload(context.Background()); !changed || err != nil {
+		t.Errorf("Reload() = %v, %v, want a change", changed, err)
+	}
+
+	wg.Wait()
+
+	if changed, err := h.Reload(context.Background()); changed || err != nil {
+		t.Errorf("Reload() = %v, %v without changes", changed, err)
+	}
+
+	if want := []int{8080, 9090}; !reflect.DeepEqual(got, want) {
+		t.Errorf("subscriber got %v, want %v", got, want)
+	}
+
+	source.set("HOLD_PORT", "broken")
+	if _, err := h.Reload(context.Background()); err == nil || h.Load().Port != 9090 {
+		t.Errorf("Reload() = %v with %+v, want an error keeping the last configuration", err, h.Load())
+	}
+}
+
+func TestHolder_Rollback(t *testing.T) {
+	type Config struct {
+		Port int `env:"PORT"`
+	}
+
+	source := &notifyingSource{values: map[string]string{"ROLL_PORT": "8080"}}
+	h, err := envs.NewHolder[Config]("ROLL", envs.WithSource(source))
+	if err != nil {
+		t.Fatalf("NewHolder() error = %v", err)
+	}
+
+	h.SetHistory(2)
+	if err := h.Rollback(); !errors.Is(err, envs.ErrNoHistory) {
+		t.Errorf("Rollback() error = %v, want %v", err, envs.ErrNoHistory)
+	}
+
+	var changes []Config
+	h.Subscribe(func(_, new Config) { changes = append(changes, new) })
+
+	for _, port := range []string{"8081", "8082"} {
+		source.set("ROLL_PORT", port)
+		if _, err := h.Reload(context.Background()); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+	}
+
+	history := h.History()
+	if len(history) != 2 || history[0].Config.Port != 8081 || history[1].Config.Port != 8082 ||
+		history[1].LoadedAt.Before(history[0].LoadedAt) {
+		t.Errorf("History() = %+v, want the last two configurations", history)
+	}
+
+	if err := h.Rollback(); err != nil || h.Load().Port != 8081 {
+		t.Errorf("Rollback() = %v with %+v, want port 8081", err, h.Load())
+	}
+
+	if err := h.Rollback(); !errors.Is(err, envs.ErrNoHistory) {
+		t.Errorf("Rollback() error = %v, want %v", err, envs.ErrNoHistory)
+	}
+
+	// the sources still hold the bad values, reloading them does not undo the rollback
+	if changed, err := h.Reload(context.Background()); changed || err != nil || h.Load().Port != 8081 {
+		t.Errorf("Reload() after Rollback() = %v, %v with %+v, want port 8081", changed, err, h.Load())
+	}
+
+	source.set("ROLL_PORT", "8083")
+	if changed, err := h.Reload(context.Background()); !changed || err != nil || h.Load().Port != 8083 {
+		t.Errorf("Reload() = %v, %v with %+v, want port 8083 once the sources change", changed, err, h.Load())
+	}
+
+	want := []Config{{8081}, {8082}, {8081}, {8083}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("subscriber got %+v, want %+v", changes, want)
+	}
+}
+
+func TestHolder_certPool(t *testing.T) {
+	type Config struct {
+		CA *x509.CertPool `env:"CA"`
+	}
+
+	cert, _ := selfSigned(t)
+	other, _ := selfSigned(t)
+
+	source := &notifyingSource{values: map[string]string{"HOLD_CA": cert}}
+	h, err := envs.NewHolder[Config]("HOLD", envs.WithSource(source))
+	if err != nil {
+		t.Fatalf("NewHolder() error = %v", err)
+	}
+
+	if changed, err := h.Reload(context.Background()); changed || err != nil {
+		t.Errorf("Reload() = %v, %v, want no change for the same bundle", changed, err)
+	}
+
+	source.set("HOLD_CA", other)
+	if changed, err := h.Reload(context.Background()); !changed || err != nil {
+		t.Errorf("Reload() = %v, %v, want a change for another bundle", changed, err)
+	}
+}
+
+func TestHolder_tlsConfig(t *testing.T) {
+	type Config struct {
+		TLS envs.TLSConfig `env:"TLS"`
+	}
+
+	cert, key := selfSigned(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	write := func(cert, key string) {
+		t.Helper()
+
+		for path, data := range map[string]string{certFile: cert, keyFile: key} {
+			if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	write(cert, key)
+	source := &notifyingSource{values: map[string]string{
+		"HOLD_TLS_CERT_FILE": certFile, "HOLD_TLS_KEY_FILE": keyFile, "HOLD_TLS_CA_FILE": certFile,
+	}}
+
+	h, err := envs.NewHolder[Config]("HOLD", envs.WithSource(source))
+	if err != nil {
+		t.Fatalf("NewHolder() error = %v", err)
+	}
+
+	if changed, err := h.Reload(context.Background()); changed || err != nil {
+		t.Errorf("Reload() = %v, %v, want no change for the same files", changed, err)
+	}
+
+	write(selfSigned(t))
+	if changed, err := h.Reload(context.Background()); !changed || err != nil {
+		t.Errorf("Reload() = %v, %v, want a change for rotated certificates", changed, err)
+	}
+}
@@ -0,0 +1,169 @@
+package envs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPSource reads values from a JSON document fetched from URL, the document is flattened into keys by joining
+// the paths of its values with . and passing them to KeyFunc, so {"server": {"port": 80}} serves SERVER_PORT.
+// arrays of scalars are joined with , and null values are missing
+type HTTPSource struct {
+	// URL is the address of the JSON document
+	URL string
+	// Header is sent with every request
+	Header http.Header
+	// Authorize can add credentials like a refreshed bearer token to the headers of every request
+	Authorize func(ctx context.Context, header http.Header) error
+	// KeyFunc turns dotted paths like server.port into keys, upper case DefaultKeyFunc is used when it is nil
+	KeyFunc KeyFunc
+	// Decode turns responses that are not plain documents into values, it replaces flattening and KeyFunc
+	Decode func(data []byte) (map[string]string, error)
+	// TTL is how long a fetched document is used, it is fetched only once when TTL is zero
+	TTL time.Duration
+	// Client sends the requests, http.DefaultClient is used when it is nil
+	Client *http.Client
+
+	mu      sync.Mutex
+	values  map[string]string
+	fetched time.Time
+}
+
+// NewHTTPSource creates an HTTPSource reading the JSON document at url
+func NewHTTPSource(url string) *HTTPSource {
+	return &HTTPSource{URL: url}
+}
+
+// Lookup reads key from the flattened document, fetching it first when it is not cached or expired
+func (s *HTTPSource) Lookup(ctx context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values == nil || (s.TTL > 0 && time.Since(s.fetched) > s.TTL) {
+		if err := s.fetch(ctx); err != nil {
+			return "", false, err
+		}
+	}
+
+	val, ok := s.values[key]
+	return val, ok, nil
+}
+
+// Refresh drops the cached document so the next lookup fetches it again
+func (s *HTTPSource) Refresh() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = nil
+}
+
+func (s *HTTPSource) fetch(ctx context.Context) error {
+	header := http.Header{"Accept": {"application/json"}}
+	for k, v := range s.Header {
+		header[k] = v
+	}
+
+	if s.Authorize != nil {
+		if err := s.Authorize(ctx, header); err != nil {
+			return fmt.Errorf("authorize: %w", err)
+		}
+	}
+
+	data, _, found, err := fetch(ctx, s.Client, http.MethodGet, s.URL, header, nil)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("%s: not found", s.URL)
+	}
+
+	if s.Decode != nil {
+		values, err := s.Decode(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.URL, err)
+		}
+
+		s.values, s.fetched = values, time.Now()
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("%s: invalid JSON: %w", s.URL, err)
+	}
+
+	keyFunc := s.KeyFunc
+	if keyFunc == nil {
+		keyFunc = func(path string) string {
+			return strings.ToUpper(DefaultKeyFunc(path))
+		}
+	}
+
+	s.values = map[string]string{}
+	flattenJSON(doc, "", func(path, val string) {
+		s.values[keyFunc(path)] = val
+	})
+	s.fetched = time.Now()
+
+	return nil
+}
+
+// flattenJSON calls set with the dotted path and text of every scalar in doc
+func flattenJSON(doc interface{}, path string, set func(path, val string)) {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			flattenJSON(child, joinKey(path, k), set)
+		}
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			text, ok := jsonScalar(item)
+			if !ok {
+				break
+			}
+
+			items = append(items, text)
+		}
+
+		if len(items) == len(v) {
+			set(path, strings.Join(items, ","))
+			return
+		}
+
+		for i, item := range v {
+			flattenJSON(item, joinKey(path, strconv.Itoa(i)), set)
+		}
+	default:
+		if text, ok := jsonScalar(v); ok && v != nil {
+			set(path, text)
+		}
+	}
+}
+
+// jsonScalar returns the text of strings, numbers and booleans, nulls are scalars without text
+func jsonScalar(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
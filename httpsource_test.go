@@ -0,0 +1,74 @@
+package envs_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func TestHTTPSource(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{
+			"name": "svc",
+			"server": {"port": 8080, "debug": true, "timeout": null},
+			"hosts": ["a", "b"],
+			"limits": [{"rate": 10}]
+		}`))
+	}))
+	defer server.Close()
+
+	type Config struct {
+		Name   string        `env:"NAME"`
+		Port   int           `env:"SERVER_PORT"`
+		Debug  bool          `env:"SERVER_DEBUG"`
+		Wait   time.Duration `env:"SERVER_TIMEOUT,default=5s"`
+		Hosts  []string      `env:"HOSTS"`
+		Limits int           `env:"LIMITS_0_RATE"`
+	}
+
+	source := envs.NewHTTPSource(server.URL)
+	source.Authorize = func(_ context.Context, header http.Header) error {
+		header.Set("Authorization", "Bearer token")
+		return nil
+	}
+
+	p := envs.NewParserOpts(envs.WithSource(source))
+	for i := 0; i < 2; i++ {
+		var got Config
+		if err := p.ParseStruct(&got, ""); err != nil {
+			t.Fatalf("ParseStruct() error = %v", err)
+		}
+
+		want := Config{Name: "svc", Port: 8080, Debug: true, Wait: 5 * time.Second, Hosts: []string{"a", "b"}, Limits: 10}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+		}
+	}
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("document fetched %d times, want 1", got)
+	}
+
+	source.Refresh()
+	if _, _, err := source.Lookup(context.Background(), "NAME"); err != nil || requests.Load() != 2 {
+		t.Errorf("Lookup() after Refresh() = %v with %d requests, want 2", err, requests.Load())
+	}
+
+	source = envs.NewHTTPSource(server.URL)
+	if _, _, err := source.Lookup(context.Background(), "NAME"); err == nil {
+		t.Error("Lookup() expected an error for an unauthorized request")
+	}
+}
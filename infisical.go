@@ -0,0 +1,50 @@
+package envs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// infisicalURL is the address of Infisical Cloud
+const infisicalURL = "https://app.infisical.com"
+
+type infisicalSecrets struct {
+	Secrets []struct {
+		Key   string `json:"secretKey"`
+		Value string `json:"secretValue"`
+	} `json:"secrets"`
+}
+
+// NewInfisicalSource creates a source reading the secrets of an Infisical project environment like dev or prod
+// with an access or service token, siteURL is the address of a self hosted instance and Infisical Cloud is used
+// when it is empty. the secrets are fetched once, set TTL on the returned source to fetch them again
+func NewInfisicalSource(siteURL, token, workspaceID, environment string) *HTTPSource {
+	if siteURL == "" {
+		siteURL = infisicalURL
+	}
+
+	query := url.Values{"workspaceId": {workspaceID}, "environment": {environment}, "secretPath": {"/"}}
+	return &HTTPSource{
+		URL: strings.TrimSuffix(siteURL, "/") + "/api/v3/secrets/raw?" + query.Encode(),
+		Authorize: func(_ context.Context, header http.Header) error {
+			header.Set("Authorization", "Bearer "+token)
+			return nil
+		},
+		Decode: func(data []byte) (map[string]string, error) {
+			var res infisicalSecrets
+			if err := json.Unmarshal(data, &res); err != nil {
+				return nil, err
+			}
+
+			values := make(map[string]string, len(res.Secrets))
+			for _, secret := range res.Secrets {
+				values[secret.Key] = secret.Value
+			}
+
+			return values, nil
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package envs_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestInfisicalSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		if req.URL.Path != "/api/v3/secrets/raw" || req.Header.Get("Authorization") != "Bearer st.token" ||
+			query.Get("workspaceId") != "ws1" || query.Get("environment") != "prod" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"secrets": [
+			{"secretKey": "API_KEY", "secretValue": "k3y"},
+			{"secretKey": "API_RETRIES", "secretValue": "3"}
+		]}`))
+	}))
+	defer server.Close()
+
+	type Config struct {
+		Key     string `env:"KEY,secret"`
+		Retries int    `env:"RETRIES"`
+		Region  string `env:"REGION,default=eu"`
+	}
+
+	source := envs.NewInfisicalSource(server.URL, "st.token", "ws1", "prod")
+	environ := envs.FromMap(map[string]string{"API_REGION": "us", "API_RETRIES": "5"})
+	p := envs.NewParser(nil, envs.Sources(environ, envs.SourceValueFunc(source)))
+
+	var got Config
+	if err := p.ParseStruct(&got, "API"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if want := (Config{Key: "k3y", Retries: 5, Region: "us"}); got != want {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package envs
+
+import (
+	"fmt"
+	r "reflect"
+	"sync"
+)
+
+// constructors holds the functions registered with RegisterInterface by interface type
+var constructors sync.Map
+
+// RegisterInterface lets fields of the interface type T be parsed, the value of the field key is passed to
+// constructor as a discriminator, like `s3` or `disk` for a Storage field. when constructor returns a pointer
+// to a struct, the fields of that struct are parsed under the key of the interface field.
+func RegisterInterface[T any](constructor func(kind string) (T, error)) {
+	t := r.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != r.Interface {
+		panic(fmt.Sprintf("envs: RegisterInterface called with %s which is not an interface", t))
+	}
+
+	constructors.Store(t, func(kind string) (any, error) {
+		return constructor(kind)
+	})
+}
+
+// parseInterface builds the value of an interface field with its registered constructor,
+// fields of interfaces without a constructor are left as they are
+func (m *Parser) parseInterface(st *decodeState, reflectValue r.Value, kind, key, path string) error {
+	constructor, ok := constructors.Load(reflectValue.Type())
+	if !ok {
+		return nil
+	}
+
+	impl, err := constructor.(func(string) (any, error))(kind)
+	if err != nil {
+		return err
+	}
+
+	v := r.ValueOf(impl)
+	if !v.IsValid() {
+		return fmt.Errorf("no %s for %q", reflectValue.Type(), kind)
+	}
+
+	if v.Kind() == r.Pointer && !v.IsNil() && isNested(v.Type()) {
+		if err = m.parseStruct(st, impl, key, path); err != nil {
+			return err
+		}
+	}
+
+	reflectValue.Set(v)
+	return nil
+}
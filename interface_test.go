@@ -0,0 +1,65 @@
+package envs_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+type storage interface {
+	Name() string
+}
+
+type diskStorage struct {
+	Path string `env:"PATH,default=/data"`
+}
+
+func (d *diskStorage) Name() string { return "disk" }
+
+type memoryStorage struct{}
+
+func (memoryStorage) Name() string { return "memory" }
+
+var errUnknownStorage = errors.New("unknown storage")
+
+func init() {
+	envs.RegisterInterface[storage](func(kind string) (storage, error) {
+		switch kind {
+		case "disk":
+			return &diskStorage{}, nil
+		case "memory":
+			return memoryStorage{}, nil
+		}
+
+		return nil, errUnknownStorage
+	})
+}
+
+func TestRegisterInterface(t *testing.T) {
+	type Config struct {
+		Storage storage `env:"STORAGE"`
+		Cache   storage `env:"CACHE"`
+		Backup  storage `env:"BACKUP"`
+	}
+
+	t.Setenv("IFACE_STORAGE", "disk")
+	t.Setenv("IFACE_STORAGE_PATH", "/var/lib/app")
+	t.Setenv("IFACE_CACHE", "memory")
+
+	cfg := Config{}
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("IFACE")); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	want := Config{Storage: &diskStorage{Path: "/var/lib/app"}, Cache: memoryStorage{}}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("got: %+v want: %+v", cfg, want)
+	}
+
+	t.Setenv("IFACE_BACKUP", "tape")
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("IFACE")); !errors.Is(err, errUnknownStorage) {
+		t.Errorf("Unmarshal() error = %v, want %v", err, errUnknownStorage)
+	}
+}
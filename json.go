@@ -0,0 +1,37 @@
+package envs
+
+import (
+	r "reflect"
+	"strings"
+)
+
+// WithJSONValues decodes the values of slice, map and struct fields that start with `[` or `{` as JSON instead of
+// the separated format, so platforms injecting JSON arrays like HOSTS=["a","b"] work without tags. a struct
+// decoded from JSON does not read its own fields keys
+func WithJSONValues() Option {
+	return func(p *Parser) {
+		p.jsonValues = true
+	}
+}
+
+// isJSONValue reports whether str should be decoded as JSON into a value of type t
+func (m *Parser) isJSONValue(t r.Type, str string) bool {
+	if !m.jsonValues {
+		return false
+	}
+
+	str = strings.TrimSpace(str)
+	if !strings.HasPrefix(str, "[") && !strings.HasPrefix(str, "{") {
+		return false
+	}
+
+	switch t.Kind() {
+	case r.Slice:
+		// []byte is base64 in JSON, a bracket there is more likely a raw value
+		return t.Elem().Kind() != r.Uint8
+	case r.Map, r.Struct:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,74 @@
+package envs_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestWithJSONValues(t *testing.T) {
+	type DB struct {
+		Host string `env:"HOST" json:"host"`
+		Port int    `env:"PORT" json:"port"`
+	}
+
+	type Config struct {
+		Hosts  []string       `env:"HOSTS"`
+		Ports  []int          `env:"PORTS"`
+		Limits map[string]int `env:"LIMITS"`
+		Tags   []string       `env:"TAGS"`
+		DB     DB             `env:"DB"`
+		Cache  *DB            `env:"CACHE"`
+	}
+
+	values := envs.FromMap(map[string]string{
+		"JSON_HOSTS":      `["a.local", "b.local"]`,
+		"JSON_PORTS":      ` [80, 443]`,
+		"JSON_LIMITS":     `{"read": 10, "write": 2}`,
+		"JSON_TAGS":       "x,y",
+		"JSON_DB":         `{"host": "db.local", "port": 5432}`,
+		"JSON_CACHE_HOST": "cache.local",
+	})
+
+	var got Config
+	err := envs.NewParserOpts(envs.WithValueFunc(values), envs.WithJSONValues()).ParseStruct(&got, "JSON")
+	if err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	want := Config{
+		Hosts:  []string{"a.local", "b.local"},
+		Ports:  []int{80, 443},
+		Limits: map[string]int{"read": 10, "write": 2},
+		Tags:   []string{"x", "y"},
+		DB:     DB{Host: "db.local", Port: 5432},
+		Cache:  &DB{Host: "cache.local"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+
+	t.Run("invalid json", func(t *testing.T) {
+		values := envs.FromMap(map[string]string{"JSON_PORTS": `[80, "https"]`})
+
+		var cfg Config
+		err := envs.NewParserOpts(envs.WithValueFunc(values), envs.WithJSONValues()).ParseStruct(&cfg, "JSON")
+		if err == nil {
+			t.Errorf("ParseStruct() expected an error for invalid JSON")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		values := envs.FromMap(map[string]string{"JSON_HOSTS": `["a.local"]`})
+
+		var cfg Config
+		if err := envs.NewParserOpts(envs.WithValueFunc(values)).ParseStruct(&cfg, "JSON"); err != nil {
+			t.Fatalf("ParseStruct() error = %v", err)
+		}
+
+		if want := []string{`["a.local"]`}; !reflect.DeepEqual(cfg.Hosts, want) {
+			t.Errorf("Hosts = %q, want %q", cfg.Hosts, want)
+		}
+	})
+}
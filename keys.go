@@ -0,0 +1,131 @@
+package envs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	r "reflect"
+	"strings"
+)
+
+// ErrEncryptedKey is returned for password protected private keys, they have to be decrypted before
+// they are handed to the parser
+var ErrEncryptedKey = errors.New("private key is encrypted")
+
+var (
+	signerType     = r.TypeOf((*crypto.Signer)(nil)).Elem()
+	rsaKeyType     = r.TypeOf(&rsa.PrivateKey{})
+	ecdsaKeyType   = r.TypeOf(&ecdsa.PrivateKey{})
+	ed25519KeyType = r.TypeOf(ed25519.PrivateKey{})
+)
+
+// filePrefix marks values holding the path of a file with the actual value, like `file=/run/secrets/key.pem`
+const filePrefix = "file="
+
+// ParsePrivateKey parses a PEM encoded private key in the PKCS #8, PKCS #1 or SEC 1 form, values starting
+// with file= are read from the path after it. blocks other than the key, like certificates, are skipped
+func ParsePrivateKey(str string) (crypto.Signer, error) {
+	data, err := readPEM(str)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			return nil, errors.New("no PEM encoded private key found")
+		}
+
+		if !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			continue
+		}
+
+		// PKCS #8 keys are encrypted with their own block type, legacy PEM encryption is announced in the headers
+		if block.Type == "ENCRYPTED PRIVATE KEY" || strings.Contains(block.Headers["Proc-Type"], "ENCRYPTED") {
+			return nil, ErrEncryptedKey
+		}
+
+		return parseKeyBlock(block)
+	}
+}
+
+// parseKeyBlock parses the DER data of a private key PEM block
+func parseKeyBlock(block *pem.Block) (crypto.Signer, error) {
+	var (
+		key interface{}
+		err error
+	)
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("malformed %s: %w", strings.ToLower(block.Type), err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%T keys can not sign", key)
+	}
+
+	return signer, nil
+}
+
+// readPEM returns the PEM data in str, or the content of the file it names with the file= prefix
+func readPEM(str string) ([]byte, error) {
+	path, ok := strings.CutPrefix(strings.TrimSpace(str), filePrefix)
+	if !ok {
+		return []byte(str), nil
+	}
+
+	return os.ReadFile(filepath.Clean(path))
+}
+
+// isKeyType reports whether t is one of the private key types ParsePrivateKey fills
+func isKeyType(t r.Type) bool {
+	return t == signerType || t == rsaKeyType || t == ecdsaKeyType || t == ed25519KeyType
+}
+
+// parseKeyValue parses the private key in str into v, which has one of the types isKeyType accepts.
+// the errors never contain str so they are kept for the secret fields keys always are
+func parseKeyValue(v r.Value, str string) error {
+	signer, err := ParsePrivateKey(str)
+	if err != nil {
+		return safeError{err: err}
+	}
+
+	key := r.ValueOf(signer)
+	if !key.Type().AssignableTo(v.Type()) {
+		return safeError{err: fmt.Errorf("private key is a %T, not a %s", signer, v.Type())}
+	}
+
+	v.Set(key)
+	return nil
+}
+
+// formatKey encodes the private key in v as PKCS #8 PEM, so ToMap output can be parsed again
+func formatKey(v r.Value) string {
+	if v.IsZero() {
+		return ""
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(v.Interface())
+	if err != nil {
+		return ""
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
@@ -0,0 +1,43 @@
+package envs
+
+import (
+	"fmt"
+	"log/slog"
+	r "reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	levelType    = r.TypeOf(slog.Level(0))
+	levelVarType = r.TypeOf(slog.LevelVar{})
+)
+
+// levelNames are the level names used by popular logging libraries that slog does not know
+var levelNames = map[string]slog.Level{
+	"trace":    slog.LevelDebug - 4,
+	"warning":  slog.LevelWarn,
+	"err":      slog.LevelError,
+	"critical": slog.LevelError + 4,
+	"fatal":    slog.LevelError + 4,
+}
+
+// ParseLevel parses log levels like debug, INFO, warn or error+2 the way slog does, the names trace, warning,
+// critical and fatal used by other logging libraries and plain numbers like -4 are accepted as well
+func ParseLevel(str string) (slog.Level, error) {
+	str = strings.TrimSpace(str)
+	if level, ok := levelNames[strings.ToLower(str)]; ok {
+		return level, nil
+	}
+
+	if n, err := strconv.Atoi(str); err == nil {
+		return slog.Level(n), nil
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(str)); err != nil {
+		return 0, fmt.Errorf("%q is not a log level", str)
+	}
+
+	return level, nil
+}
@@ -0,0 +1,62 @@
+package envs_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"Warn":    slog.LevelWarn,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+		"error+2": slog.LevelError + 2,
+		"trace":   slog.LevelDebug - 4,
+		"FATAL":   slog.LevelError + 4,
+		" -4 ":    slog.LevelDebug,
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			got, err := envs.ParseLevel(input)
+			if err != nil || got != want {
+				t.Errorf("ParseLevel(%q) = %v, %v want %v", input, got, err, want)
+			}
+		})
+	}
+
+	if _, err := envs.ParseLevel("verbose"); err == nil {
+		t.Errorf("ParseLevel() expected an error for an unknown level")
+	}
+}
+
+func TestParseStruct_level(t *testing.T) {
+	t.Setenv("LEVEL_LOG_LEVEL", "warning")
+	t.Setenv("LEVEL_DYNAMIC", "debug")
+	t.Setenv("LEVEL_BAD", "loud")
+
+	cfg := struct {
+		LogLevel slog.Level     `env:"LOG_LEVEL"`
+		Dynamic  *slog.LevelVar `env:"DYNAMIC"`
+		Default  slog.Level     `env:"DEFAULT,default=error"`
+	}{}
+	if err := envs.NewParser(nil, nil).ParseStruct(&cfg, "LEVEL"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if cfg.LogLevel != slog.LevelWarn || cfg.Dynamic.Level() != slog.LevelDebug || cfg.Default != slog.LevelError {
+		t.Errorf("ParseStruct() = %v, %v, %v want WARN, DEBUG, ERROR", cfg.LogLevel, cfg.Dynamic, cfg.Default)
+	}
+
+	if got := envs.Get[slog.Level]("LEVEL_LOG_LEVEL"); got != slog.LevelWarn {
+		t.Errorf("Get() = %v, want %v", got, slog.LevelWarn)
+	}
+
+	if _, err := envs.GetErr[slog.Level]("LEVEL_BAD"); err == nil {
+		t.Errorf("GetErr() expected an error for an unknown level")
+	}
+}
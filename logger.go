@@ -0,0 +1,123 @@
+package envs
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// EventKind tells what happened while resolving a value
+type EventKind int
+
+const (
+	// EventUnset is reported when a key is not set
+	EventUnset EventKind = iota + 1
+	// EventParseError is reported when a value can not be parsed into the requested type
+	EventParseError
+	// EventDeprecated is reported when a value is read from a key listed in a `deprecated=` tag option
+	EventDeprecated
+	// EventReloadError is reported when a reload started by a signal fails, see OnSIGHUP
+	EventReloadError
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventUnset:
+		return "unset"
+	case EventParseError:
+		return "parse error"
+	case EventDeprecated:
+		return "deprecated"
+	case EventReloadError:
+		return "reload error"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is what the package level logger receives
+type Event struct {
+	Kind EventKind
+	Key  string
+	// Err is set for EventParseError and EventReloadError, for EventDeprecated it names the key to use instead
+	Err error
+}
+
+// LoggerFunc receives events, it should not block since it is called inline
+type LoggerFunc func(Event)
+
+var logger atomic.Pointer[LoggerFunc]
+
+// SetLogger installs fn as the package level logger, the package is silent by default and a nil fn silences it again.
+func SetLogger(fn LoggerFunc) {
+	if fn == nil {
+		logger.Store(nil)
+		return
+	}
+
+	logger.Store(&fn)
+}
+
+func emit(e Event) {
+	if fn := logger.Load(); fn != nil {
+		(*fn)(e)
+	}
+}
+
+// mask hides all but the last four characters of long values and the whole of short ones
+func mask(val string) string {
+	const (
+		masked  = "****"
+		visible = 4
+		minLen  = 8
+	)
+
+	if val == "" {
+		return ""
+	}
+
+	if len(val) < minLen {
+		return masked
+	}
+
+	return masked + val[len(val)-visible:]
+}
+
+// redactedError is an error of a secret field, its message never contains the secret value
+type redactedError struct {
+	msg string
+	// err is the original error when it is a safeError, nil otherwise
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// safeError marks errors whose message never contains the value they were returned for,
+// like the ones of private keys, so they are kept for secret fields
+type safeError struct {
+	err error
+}
+
+func (e safeError) Error() string {
+	return e.err.Error()
+}
+
+func (e safeError) Unwrap() error {
+	return e.err
+}
+
+// redactError describes err without its message, which can quote the value or a part of it like a single
+// list item or map key, only safeErrors are kept as they are
+func redactError(err error, key string) error {
+	var safe safeError
+	if errors.As(err, &safe) {
+		return &redactedError{msg: key + ": " + err.Error(), err: err}
+	}
+
+	return &redactedError{msg: key + ": invalid value"}
+}
@@ -0,0 +1,75 @@
+package envs_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestSetLogger(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events = map[string]envs.Event{}
+	)
+
+	envs.SetLogger(func(e envs.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events[e.Key] = e
+	})
+	defer envs.SetLogger(nil)
+
+	t.Setenv("LOGGER_BAD_PORT", "eighty")
+
+	envs.Get[int]("LOGGER_UNSET_PORT")
+	envs.Get[int]("LOGGER_BAD_PORT")
+	envs.NewMapGetter(nil).GetInt("LOGGER_GETTER_PORT", 0)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if e := events["LOGGER_UNSET_PORT"]; e.Kind != envs.EventUnset {
+		t.Errorf("event kind = %v, want %v", e.Kind, envs.EventUnset)
+	}
+
+	if e := events["LOGGER_BAD_PORT"]; e.Kind != envs.EventParseError || e.Err == nil {
+		t.Errorf("event = %v, want %v with an error", e, envs.EventParseError)
+	}
+
+	if e := events["LOGGER_GETTER_PORT"]; e.Kind != envs.EventUnset {
+		t.Errorf("event kind = %v, want %v", e.Kind, envs.EventUnset)
+	}
+}
+
+func TestParseStruct_deprecated(t *testing.T) {
+	var events []envs.Event
+	envs.SetLogger(func(e envs.Event) {
+		events = append(events, e)
+	})
+	defer envs.SetLogger(nil)
+
+	type Config struct {
+		URL  string `env:"DATABASE_URL,deprecated=DB_URL|DB_DSN"`
+		Port int    `env:"PORT,deprecated=LISTEN_PORT,default=80"`
+	}
+
+	t.Setenv("DEP_DB_DSN", "postgres://db")
+	t.Setenv("DEP_LISTEN_PORT", "8080")
+	t.Setenv("DEP_PORT", "9090")
+
+	cfg := Config{}
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("DEP")); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if cfg.URL != "postgres://db" || cfg.Port != 9090 {
+		t.Errorf("got: %+v", cfg)
+	}
+
+	if len(events) != 1 || events[0].Kind != envs.EventDeprecated || events[0].Key != "DEP_DB_DSN" ||
+		events[0].Err == nil || !strings.Contains(events[0].Err.Error(), "DEP_DATABASE_URL") {
+		t.Errorf("events = %v, want a single deprecated event for DEP_DB_DSN", events)
+	}
+}
@@ -0,0 +1,63 @@
+package envs
+
+import (
+	"bytes"
+	"fmt"
+	r "reflect"
+	"strings"
+)
+
+// Markdown returns a Markdown table documenting every key the parser reads from cfg,
+// descriptions come from the `desc=` tag option like `env:"PORT,default=8080,desc=port to listen on"`.
+func Markdown(cfg interface{}, prefix string) ([]byte, error) {
+	return NewParser(nil, nil).Markdown(cfg, prefix)
+}
+
+// Markdown returns a Markdown table documenting every key the parser reads from cfg,
+// an empty prefix falls back to the one configured with WithPrefix.
+func (m *Parser) Markdown(cfg interface{}, prefix string) ([]byte, error) {
+	if prefix == "" {
+		prefix = m.prefix
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("| Key | Type | Default | Required | Description |\n")
+	buf.WriteString("| --- | --- | --- | --- | --- |\n")
+
+	err := m.walk(r.ValueOf(cfg), prefix, "", func(f field) error {
+		required := "no"
+		if m.required(f.Tag) {
+			required = "yes"
+		}
+
+		def := ""
+		if f.Tag.def != "" {
+			def = "`" + markdownCell(f.Tag.def) + "`"
+		}
+
+		desc := markdownCell(f.Tag.desc)
+		if f.Tag.example != "" {
+			desc = strings.TrimSpace(desc + " (example: `" + markdownCell(f.Tag.example) + "`)")
+		}
+
+		typ := "`" + markdownCell(f.Type.String()) + "`"
+		if f.Tag.octal {
+			typ += " (base 8)"
+		}
+
+		_, err := fmt.Fprintf(buf, "| `%s` | %s | %s | %s | %s |\n", f.Key, typ, def, required, desc)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// markdownCell escapes text so it stays inside a single table cell
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
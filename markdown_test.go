@@ -0,0 +1,35 @@
+package envs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func TestMarkdown(t *testing.T) {
+	type Config struct {
+		Port    int               `env:"PORT,default=8080,desc=port to listen on"`
+		Token   string            `env:"TOKEN,required,desc=api token, from the dashboard,example='tok_1'"`
+		Routes  map[string]string `env:"ROUTES,default=a:b,c:d"`
+		Timeout time.Duration
+		Umask   int `env:"UMASK,octal"`
+	}
+
+	want := "| Key | Type | Default | Required | Description |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| `APP_PORT` | `int` | `8080` | no | port to listen on |\n" +
+		"| `APP_TOKEN` | `string` |  | yes | api token, from the dashboard (example: `tok_1`) |\n" +
+		"| `APP_ROUTES` | `map[string]string` | `a:b,c:d` | no |  |\n" +
+		"| `APP_TIMEOUT` | `time.Duration` |  | no |  |\n" +
+		"| `APP_UMASK` | `int` (base 8) |  | no |  |\n"
+
+	got, err := envs.Markdown(Config{}, "APP")
+	if err != nil {
+		t.Fatalf("Markdown() error = %v", err)
+	}
+
+	if string(got) != want {
+		t.Errorf("Markdown() got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,191 @@
+package envs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// onePasswordScheme starts 1Password secret references like op://vault/item/field
+const onePasswordScheme = "op://"
+
+// OnePassword resolves 1Password secret references like op://vault/item/field or op://vault/item/section/field
+// with the op CLI, or with a 1Password Connect server when ConnectHost and ConnectToken are set.
+// use its Resolve method with WithValueHook, resolved references are cached
+type OnePassword struct {
+	// Command is the path of the op CLI, op is looked up in PATH when it is empty
+	Command string
+	// ConnectHost is the base URL of a 1Password Connect server
+	ConnectHost string
+	// ConnectToken is the access token of the Connect server
+	ConnectToken string
+	// Client sends the Connect requests, http.DefaultClient is used when it is nil
+	Client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// Resolve returns the secret value references point to and any other value unchanged, it is a ValueHook
+func (o *OnePassword) Resolve(ctx context.Context, key, value string) (string, error) {
+	if !strings.HasPrefix(value, onePasswordScheme) {
+		return value, nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if secret, ok := o.cache[value]; ok {
+		return secret, nil
+	}
+
+	var (
+		secret string
+		err    error
+	)
+
+	if o.ConnectHost != "" && o.ConnectToken != "" {
+		secret, err = o.connect(ctx, value)
+	} else {
+		secret, err = o.read(ctx, value)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", value, err)
+	}
+
+	if o.cache == nil {
+		o.cache = map[string]string{}
+	}
+
+	o.cache[value] = secret
+	return secret, nil
+}
+
+// read runs op read
+func (o *OnePassword) read(ctx context.Context, ref string) (string, error) {
+	command := o.Command
+	if command == "" {
+		command = "op"
+	}
+
+	var stderr bytes.Buffer
+	// #nosec G204 -- the reference is passed as a single argument to the configured op CLI
+	cmd := exec.CommandContext(ctx, command, "read", "--no-newline", ref)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+type onePasswordObject struct {
+	ID string `json:"id"`
+}
+
+type onePasswordSection struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type onePasswordItem struct {
+	Fields []struct {
+		ID      string              `json:"id"`
+		Label   string              `json:"label"`
+		Value   string              `json:"value"`
+		Section *onePasswordSection `json:"section"`
+	} `json:"fields"`
+	Sections []onePasswordSection `json:"sections"`
+}
+
+// connect reads the field ref points to from the Connect server
+func (o *OnePassword) connect(ctx context.Context, ref string) (string, error) {
+	parts := strings.Split(strings.TrimPrefix(ref, onePasswordScheme), "/")
+	if len(parts) != 3 && len(parts) != 4 {
+		return "", errors.New("references have to look like op://vault/item/[section/]field")
+	}
+
+	var vaults []onePasswordObject
+	filter := url.QueryEscape(fmt.Sprintf("name eq %q", parts[0]))
+	if err := o.get(ctx, "/v1/vaults?filter="+filter, &vaults); err != nil {
+		return "", err
+	}
+
+	if len(vaults) == 0 {
+		return "", fmt.Errorf("vault %s not found", parts[0])
+	}
+
+	var items []onePasswordObject
+	path := "/v1/vaults/" + url.PathEscape(vaults[0].ID) + "/items"
+	filter = url.QueryEscape(fmt.Sprintf("title eq %q", parts[1]))
+	if err := o.get(ctx, path+"?filter="+filter, &items); err != nil {
+		return "", err
+	}
+
+	if len(items) == 0 {
+		return "", fmt.Errorf("item %s not found", parts[1])
+	}
+
+	var item onePasswordItem
+	if err := o.get(ctx, path+"/"+url.PathEscape(items[0].ID), &item); err != nil {
+		return "", err
+	}
+
+	section, field := "", parts[len(parts)-1]
+	if len(parts) == 4 {
+		section = parts[2]
+	}
+
+	for _, f := range item.Fields {
+		if (f.Label != field && f.ID != field) || (section != "" && !item.inSection(f.Section, section)) {
+			continue
+		}
+
+		return f.Value, nil
+	}
+
+	return "", fmt.Errorf("field %s not found", field)
+}
+
+// inSection reports whether section is the one labeled or identified by name
+func (item onePasswordItem) inSection(section *onePasswordSection, name string) bool {
+	if section == nil {
+		return false
+	}
+
+	for _, s := range item.Sections {
+		if s.ID == section.ID && (s.Label == name || s.ID == name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *OnePassword) get(ctx context.Context, path string, dest interface{}) error {
+	header := http.Header{"Authorization": {"Bearer " + o.ConnectToken}}
+	data, _, found, err := fetch(ctx, o.Client, http.MethodGet, strings.TrimSuffix(o.ConnectHost, "/")+path, header, nil)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("%s not found", path)
+	}
+
+	return json.Unmarshal(data, dest)
+}
@@ -0,0 +1,103 @@
+package envs_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestOnePasswordCLI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake op CLI is a shell script")
+	}
+
+	op := filepath.Join(t.TempDir(), "op")
+	script := "#!/bin/sh\n[ \"$1 $2 $3\" = \"read --no-newline op://dev/db/password\" ] ||\n" +
+		"{ echo \"no such item\" >&2; exit 1; }\nprintf s3cret\n"
+	if err := os.WriteFile(op, []byte(script), 0o700); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	type Config struct {
+		Password string `env:"PASSWORD,secret"`
+		User     string `env:"USER,default=admin"`
+	}
+
+	values := envs.FromMap(map[string]string{"DB_PASSWORD": "op://dev/db/password"})
+	resolver := &envs.OnePassword{Command: op}
+	p := envs.NewParserOpts(envs.WithValueFunc(values), envs.WithValueHook(resolver.Resolve))
+
+	var got Config
+	if err := p.ParseStruct(&got, "DB"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if want := (Config{Password: "s3cret", User: "admin"}); got != want {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+
+	if _, err := resolver.Resolve(context.Background(), "KEY", "op://dev/missing/password"); err == nil {
+		t.Error("Resolve() expected an error for a missing item")
+	}
+}
+
+func TestOnePasswordConnect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var res interface{}
+		switch req.URL.Path {
+		case "/v1/vaults":
+			if req.URL.Query().Get("filter") == `name eq "dev"` {
+				res = []map[string]string{{"id": "v1"}}
+			}
+		case "/v1/vaults/v1/items":
+			if req.URL.Query().Get("filter") == `title eq "db"` {
+				res = []map[string]string{{"id": "i1"}}
+			}
+		case "/v1/vaults/v1/items/i1":
+			res = map[string]interface{}{
+				"sections": []map[string]string{{"id": "s1", "label": "replica"}},
+				"fields": []map[string]interface{}{
+					{"id": "f1", "label": "password", "value": "primary"},
+					{"id": "f2", "label": "password", "value": "replica", "section": map[string]string{"id": "s1"}},
+				},
+			}
+		}
+
+		if res == nil {
+			res = []string{}
+		}
+
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer server.Close()
+
+	resolver := &envs.OnePassword{ConnectHost: server.URL, ConnectToken: "token"}
+	tests := map[string]string{
+		"op://dev/db/password":         "primary",
+		"op://dev/db/replica/password": "replica",
+		"plain":                        "plain",
+	}
+
+	for ref, want := range tests {
+		got, err := resolver.Resolve(context.Background(), "KEY", ref)
+		if err != nil || got != want {
+			t.Errorf("Resolve(%s) = %q, %v, want %q", ref, got, err, want)
+		}
+	}
+
+	if _, err := resolver.Resolve(context.Background(), "KEY", "op://prod/db/password"); err == nil {
+		t.Error("Resolve() expected an error for a missing vault")
+	}
+}
@@ -0,0 +1,193 @@
+package envs
+
+import "log/slog"
+
+// Option configures a Parser created by NewParserOpts
+type Option func(*Parser)
+
+// NewParserOpts creates a Parser with the default key and value functions and applies opts on top of it.
+func NewParserOpts(opts ...Option) *Parser {
+	p := NewParser(nil, nil)
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// WithPrefix sets the prefix used when ParseStruct is called with an empty prefix
+func WithPrefix(prefix string) Option {
+	return func(p *Parser) {
+		p.prefix = prefix
+	}
+}
+
+// WithTagName changes the struct tag the parser reads keys and defaults from, default is `env`
+func WithTagName(name string) Option {
+	return func(p *Parser) {
+		if name != "" {
+			p.tagName = name
+		}
+	}
+}
+
+// WithSeparators replaces the separators used for splitting slice and map values,
+// separators are tried in order and the first one found in the value is used.
+func WithSeparators(separators ...string) Option {
+	return func(p *Parser) {
+		if len(separators) > 0 {
+			p.separators = separators
+		}
+	}
+}
+
+// WithStrict makes the parser return ErrNotSet for fields that have neither a value nor a default
+func WithStrict() Option {
+	return func(p *Parser) {
+		p.strict = true
+	}
+}
+
+// WithValueFunc sets the function used for reading values, empty values count as missing with it
+func WithValueFunc(valueFunc ValueFunc) Option {
+	return func(p *Parser) {
+		if valueFunc != nil {
+			p.Get = valueFunc
+			p.lookupFunc = nil
+			p.sourceName = customSource
+		}
+	}
+}
+
+// WithValueFuncCtx sets a context aware function used for reading values, it takes over the one set by
+// WithValueFunc and receives the context given to ParseStructCtx
+func WithValueFuncCtx(valueFunc ValueFuncCtx) Option {
+	return func(p *Parser) {
+		if valueFunc != nil {
+			p.getCtx = valueFunc
+			p.lookupFunc = nil
+			p.sourceName = customSource
+		}
+	}
+}
+
+// WithLookupFunc sets a function used for reading values that reports whether a key is set, defaults are only
+// used for keys that are not found. it takes over the functions set by WithValueFunc and WithValueFuncCtx
+func WithLookupFunc(lookupFunc LookupFunc) Option {
+	return func(p *Parser) {
+		if lookupFunc != nil {
+			p.lookupFunc = lookupFunc
+			p.sourceName = customSource
+		}
+	}
+}
+
+// WithSource sets the Source values are read from, defaults are only used for keys it does not find.
+// it takes over the functions set by WithValueFunc, WithValueFuncCtx and WithLookupFunc
+func WithSource(source Source) Option {
+	return func(p *Parser) {
+		if source != nil {
+			p.source = source
+			p.sourceName = customSource
+		}
+	}
+}
+
+// WithValueHook adds a hook that rewrites non-empty values, including defaults, before they are parsed.
+// hooks run in the order they were added and resolutions keep the values from before them
+func WithValueHook(hook ValueHook) Option {
+	return func(p *Parser) {
+		if hook != nil {
+			p.hooks = append(p.hooks, hook)
+		}
+	}
+}
+
+// WithEmptyAsUnset treats variables set to an empty string as missing, so their defaults are used,
+// which was the behavior before presence based lookups
+func WithEmptyAsUnset() Option {
+	return func(p *Parser) {
+		p.emptyAsUnset = true
+	}
+}
+
+// WithPreserveValues keeps the non-zero values a destination already has when their keys are not found,
+// they take precedence over tag defaults so defaults set in code can be overridden by the environment
+func WithPreserveValues() Option {
+	return func(p *Parser) {
+		p.preserve = true
+	}
+}
+
+// WithResetUnset zeroes fields whose keys are not found and have no default, so a struct parsed again on reload
+// does not keep values of variables that were removed since. WithPreserveValues takes precedence over it
+func WithResetUnset() Option {
+	return func(p *Parser) {
+		p.reset = true
+	}
+}
+
+// WithCaseInsensitive matches environment variables regardless of case, like `app_port` for APP_PORT, using a
+// snapshot of os.Environ taken at the start of every parse. exact matches win, it has no effect on value functions
+func WithCaseInsensitive() Option {
+	return func(p *Parser) {
+		p.caseInsensitive = true
+	}
+}
+
+// WithKeyFunc sets the function used for building keys
+func WithKeyFunc(keyFunc KeyFunc) Option {
+	return func(p *Parser) {
+		if keyFunc != nil {
+			p.BuildKey = keyFunc
+		}
+	}
+}
+
+// WithDelimiter joins the fields of nested structs to the key of their struct with delimiter instead of `_`,
+// so with `__` the ReadTimeout field of a Server struct under the APP prefix reads APP_SERVER__READ_TIMEOUT.
+// the prefix is joined as before and the key function still builds the keys
+func WithDelimiter(delimiter string) Option {
+	return func(p *Parser) {
+		p.delimiter = delimiter
+	}
+}
+
+// WithKeyCasing changes how the keys of fields without a tag are derived from their names,
+// sources other than the environment often use other conventions than ScreamingSnakeCase
+func WithKeyCasing(casing KeyCasing) Option {
+	return func(p *Parser) {
+		p.casing = casing
+	}
+}
+
+// WithRequireTag only reads fields that have a tag, untagged fields are skipped instead of being read from keys
+// derived from their names
+func WithRequireTag() Option {
+	return func(p *Parser) {
+		p.requireTag = true
+	}
+}
+
+// WithFieldKeyFunc sets the function deriving the dotted key of every struct field from the field itself,
+// its path and the key of its parent, instead of the tag or the field name
+func WithFieldKeyFunc(fieldKeyFunc FieldKeyFunc) Option {
+	return func(p *Parser) {
+		p.fieldKeyFunc = fieldKeyFunc
+	}
+}
+
+// WithLogger logs every key lookup at debug level, values are always masked
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *Parser) {
+		p.logger = logger
+	}
+}
+
+// WithConcurrency makes ParseStruct fetch the values of all fields with up to n parallel calls to the value function
+// before assigning them, which speeds up slow remote sources. the value function has to be safe for concurrent use.
+func WithConcurrency(n int) Option {
+	return func(p *Parser) {
+		p.concurrency = n
+	}
+}
@@ -0,0 +1,594 @@
+package envs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func TestNewParserOpts(t *testing.T) {
+	values := map[string]string{
+		"APP_NAME":  "service",
+		"APP_PORTS": "80|443",
+		"APP_HOST":  "localhost",
+	}
+
+	valueFunc := func(key, def string) string {
+		if val, ok := values[key]; ok {
+			return val
+		}
+
+		return def
+	}
+
+	type Config struct {
+		Name  string `cfg:"NAME"`
+		Ports []int  `cfg:"PORTS"`
+		Host  string `env:"SERVER_HOST"`
+	}
+
+	t.Run("options are applied", func(t *testing.T) {
+		cfg := Config{}
+		p := envs.NewParserOpts(
+			envs.WithPrefix("APP"),
+			envs.WithTagName("cfg"),
+			envs.WithSeparators("|"),
+			envs.WithValueFunc(valueFunc),
+		)
+
+		if err := p.ParseStruct(&cfg, ""); err != nil {
+			t.Fatalf("ParseStruct() error = %v", err)
+		}
+
+		want := Config{Name: "service", Ports: []int{80, 443}, Host: "localhost"}
+		if !reflect.DeepEqual(cfg, want) {
+			t.Errorf("got: %v want: %v", cfg, want)
+		}
+	})
+
+	t.Run("strict mode reports missing values", func(t *testing.T) {
+		cfg := struct {
+			Missing string `env:"MISSING"`
+		}{}
+
+		p := envs.NewParserOpts(envs.WithPrefix("APP"), envs.WithStrict(), envs.WithValueFunc(valueFunc))
+		if err := p.ParseStruct(&cfg, ""); !errors.Is(err, envs.ErrNotSet) {
+			t.Errorf("ParseStruct() error = %v, want %v", err, envs.ErrNotSet)
+		}
+	})
+
+	t.Run("key func is used", func(t *testing.T) {
+		cfg := struct {
+			Name string `env:"NAME"`
+		}{}
+
+		p := envs.NewParserOpts(
+			envs.WithKeyFunc(func(key string) string { return "APP_NAME" }),
+			envs.WithValueFunc(valueFunc),
+		)
+		if err := p.ParseStruct(&cfg, ""); err != nil {
+			t.Fatalf("ParseStruct() error = %v", err)
+		}
+
+		if cfg.Name != "service" {
+			t.Errorf("got: %v want: %v", cfg.Name, "service")
+		}
+	})
+}
+
+func TestWithLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	cfg := struct {
+		Token string `env:"TOKEN"`
+		Port  int    `env:"PORT,default=8080"`
+	}{}
+
+	values := map[string]string{"LOG_TOKEN": "super-secret-1234"}
+	p := envs.NewParserOpts(envs.WithLogger(logger), envs.WithValueFunc(func(key, def string) string {
+		if val, ok := values[key]; ok {
+			return val
+		}
+
+		return def
+	}))
+
+	if err := p.ParseStruct(&cfg, "LOG"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "super-secret") {
+		t.Errorf("log output leaks the value: %s", out)
+	}
+
+	for _, want := range []string{`"key":"LOG_TOKEN"`, `"value":"****1234"`, `"key":"LOG_PORT"`, `"source":"default"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output %s does not contain %s", out, want)
+		}
+	}
+}
+
+func TestWithSeparators_order(t *testing.T) {
+	tests := []struct {
+		value      string
+		separators []string
+		want       []string
+	}{
+		{value: "a;b,c", want: []string{"a;b", "c"}},
+		{value: "a;b c", want: []string{"a", "b c"}},
+		{value: "abc", want: []string{"abc"}},
+		{value: "a::b|c::d", separators: []string{"::", "|"}, want: []string{"a", "b|c", "d"}},
+		{value: "a|b", separators: []string{"::", "|"}, want: []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cfg := struct {
+				List []string `env:"LIST"`
+			}{}
+
+			opts := []envs.Option{envs.WithValueFunc(func(key, def string) string { return tt.value })}
+			if tt.separators != nil {
+				opts = append(opts, envs.WithSeparators(tt.separators...))
+			}
+
+			if err := envs.Unmarshal(&cfg, opts...); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(cfg.List, tt.want) {
+				t.Errorf("got: %q want: %q", cfg.List, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithConcurrency(t *testing.T) {
+	type Config struct {
+		Name   string `env:"NAME"`
+		Port   int    `env:"PORT,default=8080"`
+		Server struct {
+			Host    string        `env:"HOST"`
+			Timeout time.Duration `env:"TIMEOUT"`
+		} `env:"SERVER"`
+	}
+
+	values := map[string]string{"APP_NAME": "svc", "APP_SERVER_HOST": "localhost", "APP_SERVER_TIMEOUT": "1s"}
+
+	var (
+		mu      sync.Mutex
+		calls   = map[string]int{}
+		running int32
+		peak    int32
+	)
+
+	valueFunc := func(key, def string) string {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		calls[key]++
+		mu.Unlock()
+
+		return values[key]
+	}
+
+	cfg := Config{}
+	p := envs.NewParserOpts(envs.WithPrefix("APP"), envs.WithValueFunc(valueFunc), envs.WithConcurrency(2))
+	if err := p.ParseStruct(&cfg, ""); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	want := Config{Name: "svc", Port: 8080}
+	want.Server.Host = "localhost"
+	want.Server.Timeout = time.Second
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("got: %+v want: %+v", cfg, want)
+	}
+
+	for key, n := range calls {
+		if n != 1 {
+			t.Errorf("%s was read %d times, want once", key, n)
+		}
+	}
+
+	if len(calls) != 5 {
+		t.Errorf("read %d keys, want 5: %v", len(calls), calls)
+	}
+
+	if peak > 2 {
+		t.Errorf("%d parallel reads, want at most 2", peak)
+	}
+}
+
+func TestWithLookupFunc(t *testing.T) {
+	type Config struct {
+		Name  string `env:"NAME,default=svc"`
+		Level string `env:"LEVEL,default=info"`
+	}
+
+	errLookup := errors.New("connection refused")
+	values := map[string]string{"LOOKUP_NAME": ""}
+	p := envs.NewParserOpts(envs.WithLookupFunc(func(key, def string) (string, bool, error) {
+		if key == "BROKEN_NAME" {
+			return "", false, errLookup
+		}
+
+		val, ok := values[key]
+		return val, ok, nil
+	}))
+
+	cfg := Config{}
+	if err := p.ParseStruct(&cfg, "LOOKUP"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if want := (Config{Level: "info"}); cfg != want {
+		t.Errorf("got: %+v want: %+v", cfg, want)
+	}
+
+	want := []envs.Resolution{
+		{FieldPath: "Name", Key: "LOOKUP_NAME", Source: "custom"},
+		{FieldPath: "Level", Key: "LOOKUP_LEVEL", Source: "default", UsedDefault: true, Raw: "info"},
+	}
+
+	if got := p.Resolutions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Resolutions() = %+v, want %+v", got, want)
+	}
+
+	if err := p.ParseStruct(&cfg, "BROKEN"); !errors.Is(err, errLookup) {
+		t.Errorf("ParseStruct() error = %v, want %v", err, errLookup)
+	}
+}
+
+func TestWithFieldKeyFunc(t *testing.T) {
+	type Config struct {
+		Name   string `json:"service_name"`
+		Server struct {
+			Port int `json:"port"`
+		} `json:"http"`
+	}
+
+	values := map[string]string{"APP_SERVICE_NAME": "svc", "APP_HTTP_PORT": "8080"}
+
+	var paths []string
+	p := envs.NewParserOpts(
+		envs.WithValueFunc(func(key, def string) string { return values[key] }),
+		envs.WithFieldKeyFunc(func(field reflect.StructField, path, prefix string) string {
+			paths = append(paths, path)
+			return prefix + "." + strings.ToUpper(field.Tag.Get("json"))
+		}),
+	)
+
+	cfg := Config{}
+	if err := p.ParseStruct(&cfg, "APP"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if cfg.Name != "svc" || cfg.Server.Port != 8080 {
+		t.Errorf("got: %+v", cfg)
+	}
+
+	if want := []string{"Name", "Server", "Server.Port"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+}
+
+func TestWithPreserveValues(t *testing.T) {
+	type Config struct {
+		Name    string        `env:"NAME,default=svc"`
+		Port    int           `env:"PORT,default=8080"`
+		Timeout time.Duration `env:"TIMEOUT,default=1s"`
+		Token   string        `env:"TOKEN,secret"`
+	}
+
+	t.Setenv("PRESET_PORT", "9090")
+
+	cfg := Config{Name: "code", Port: 1, Token: "long-secret-token"}
+	p := envs.NewParserOpts(envs.WithPrefix("PRESET"), envs.WithPreserveValues())
+	if err := p.ParseStruct(&cfg, ""); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	want := Config{Name: "code", Port: 9090, Timeout: time.Second, Token: "long-secret-token"}
+	if cfg != want {
+		t.Errorf("got: %+v want: %+v", cfg, want)
+	}
+
+	wantRes := []envs.Resolution{
+		{FieldPath: "Name", Key: "PRESET_NAME", Source: "preset", Raw: "code"},
+		{FieldPath: "Port", Key: "PRESET_PORT", Source: "env", Raw: "9090"},
+		{FieldPath: "Timeout", Key: "PRESET_TIMEOUT", Source: "default", UsedDefault: true, Raw: "1s"},
+		{FieldPath: "Token", Key: "PRESET_TOKEN", Source: "preset", Raw: "****oken"},
+	}
+
+	if got := p.Resolutions(); !reflect.DeepEqual(got, wantRes) {
+		t.Errorf("Resolutions() = %+v, want %+v", got, wantRes)
+	}
+}
+
+func TestWithResetUnset(t *testing.T) {
+	type Config struct {
+		Name    string    `env:"NAME"`
+		Port    int       `env:"PORT,default=8080"`
+		Started time.Time `env:"STARTED"`
+		Hosts   []string  `env:"HOSTS"`
+		Server  struct {
+			Host string `env:"HOST"`
+		} `env:"SERVER"`
+	}
+
+	t.Setenv("RESET_NAME", "svc")
+
+	stale := Config{Name: "old", Port: 1, Started: time.Now(), Hosts: []string{"a"}}
+	stale.Server.Host = "localhost"
+
+	cfg := stale
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("RESET"), envs.WithResetUnset()); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if want := (Config{Name: "svc", Port: 8080}); !reflect.DeepEqual(cfg, want) {
+		t.Errorf("got: %+v want: %+v", cfg, want)
+	}
+
+	cfg = stale
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("RESET")); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if cfg.Server.Host != "localhost" || len(cfg.Hosts) != 1 {
+		t.Errorf("without WithResetUnset fields should be kept, got: %+v", cfg)
+	}
+}
+
+func TestWithCaseInsensitive(t *testing.T) {
+	type Config struct {
+		Name string `env:"NAME"`
+		Port int    `env:"PORT"`
+	}
+
+	t.Setenv("fold_name", "svc")
+	t.Setenv("Fold_Port", "80")
+	t.Setenv("FOLD_PORT", "8080")
+
+	cfg := Config{}
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("FOLD"), envs.WithCaseInsensitive()); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if want := (Config{Name: "svc", Port: 8080}); cfg != want {
+		t.Errorf("got: %+v want: %+v", cfg, want)
+	}
+
+	// the environment of windows is case insensitive on its own
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	cfg = Config{}
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("FOLD")); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if cfg.Name != "" {
+		t.Errorf("without WithCaseInsensitive Name = %q, want it empty", cfg.Name)
+	}
+}
+
+func TestWithDelimiter(t *testing.T) {
+	type Config struct {
+		Port   int
+		Server struct {
+			ReadTimeout time.Duration
+			TLS         struct {
+				Cert string
+			}
+		}
+	}
+
+	t.Setenv("DELIM_PORT", "8080")
+	t.Setenv("DELIM_SERVER__READ_TIMEOUT", "3s")
+	t.Setenv("DELIM_SERVER__TLS__CERT", "cert.pem")
+	t.Setenv("delim_lower_server__read_timeout", "5s")
+
+	cfg := Config{}
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("DELIM"), envs.WithDelimiter("__")); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if cfg.Port != 8080 || cfg.Server.ReadTimeout != 3*time.Second || cfg.Server.TLS.Cert != "cert.pem" {
+		t.Errorf("Unmarshal() = %+v, want port 8080, 3s and cert.pem", cfg)
+	}
+
+	lower := envs.WithKeyFunc(func(key string) string {
+		return strings.ToLower(envs.DefaultKeyFunc(key))
+	})
+
+	// the delimiter keeps working whichever of the options comes first
+	for _, opts := range [][]envs.Option{
+		{envs.WithPrefix("DELIM_LOWER"), envs.WithDelimiter("__"), lower},
+		{envs.WithPrefix("DELIM_LOWER"), lower, envs.WithDelimiter("__"), envs.WithConcurrency(2)},
+	} {
+		cfg = Config{}
+		if err := envs.Unmarshal(&cfg, opts...); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if cfg.Server.ReadTimeout != 5*time.Second {
+			t.Errorf("ReadTimeout = %v with WithKeyFunc, want 5s", cfg.Server.ReadTimeout)
+		}
+	}
+}
+
+func TestWithKeyCasing(t *testing.T) {
+	type Config struct {
+		ReadTimeout string
+		Server      struct {
+			MaxConn string
+		}
+	}
+
+	tests := []struct {
+		casing envs.KeyCasing
+		want   []string
+	}{
+		{envs.ScreamingSnakeCase, []string{"APP_READ_TIMEOUT", "APP_SERVER", "APP_SERVER_MAX_CONN"}},
+		{envs.SnakeCase, []string{"APP_read_timeout", "APP_server", "APP_server_max_conn"}},
+		{envs.KebabCase, []string{"APP_read-timeout", "APP_server", "APP_server_max-conn"}},
+		{envs.CamelCase, []string{"APP_readTimeout", "APP_server", "APP_server_maxConn"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want[0], func(t *testing.T) {
+			var keys []string
+			p := envs.NewParserOpts(envs.WithKeyCasing(tt.casing), envs.WithValueFunc(func(key, def string) string {
+				keys = append(keys, key)
+				return ""
+			}))
+
+			if err := p.ParseStruct(&Config{}, "APP"); err != nil {
+				t.Fatalf("ParseStruct() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("keys = %v, want %v", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRequireTag(t *testing.T) {
+	type Config struct {
+		Name     string `env:"NAME"`
+		Internal string
+		Server   struct {
+			Port int `env:"PORT"`
+		}
+		DB struct {
+			Host string `env:"HOST"`
+		} `envPrefix:"DB"`
+	}
+
+	t.Setenv("TAGGED_NAME", "svc")
+	t.Setenv("TAGGED_INTERNAL", "leak")
+	t.Setenv("TAGGED_SERVER_PORT", "8080")
+	t.Setenv("TAGGED_DB_HOST", "db")
+
+	cfg := Config{}
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("TAGGED"), envs.WithRequireTag()); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if cfg.Name != "svc" || cfg.Internal != "" || cfg.Server.Port != 0 || cfg.DB.Host != "db" {
+		t.Errorf("got: %+v", cfg)
+	}
+}
+
+type ctxKey struct{}
+
+func TestWithSource(t *testing.T) {
+	type Config struct {
+		Name  string `env:"NAME,default=svc"`
+		Level string `env:"LEVEL,default=info"`
+	}
+
+	errLookup := errors.New("connection refused")
+	values := map[string]string{"SOURCE_NAME": ""}
+	source := envs.SourceFunc(func(ctx context.Context, key string) (string, bool, error) {
+		if ctx.Value(ctxKey{}) == nil {
+			t.Errorf("Lookup(%s) did not receive the parse context", key)
+		}
+
+		if key == "BROKEN_NAME" {
+			return "", false, errLookup
+		}
+
+		val, ok := values[key]
+		return val, ok, nil
+	})
+
+	p := envs.NewParserOpts(envs.WithValueFunc(envs.FromMap(map[string]string{"SOURCE_LEVEL": "debug"})),
+		envs.WithSource(source))
+	ctx := context.WithValue(context.Background(), ctxKey{}, true)
+
+	cfg := Config{}
+	if err := p.ParseStructCtx(ctx, &cfg, "SOURCE"); err != nil {
+		t.Fatalf("ParseStructCtx() error = %v", err)
+	}
+
+	if want := (Config{Level: "info"}); cfg != want {
+		t.Errorf("got: %+v want: %+v", cfg, want)
+	}
+
+	if err := p.ParseStructCtx(ctx, &cfg, "BROKEN"); !errors.Is(err, errLookup) {
+		t.Errorf("ParseStructCtx() error = %v, want %v", err, errLookup)
+	}
+}
+
+func TestWithValueHook(t *testing.T) {
+	type Config struct {
+		Name  string `env:"NAME"`
+		Level string `env:"LEVEL,default=ref:level"`
+		Host  string `env:"HOST"`
+	}
+
+	refs := map[string]string{"ref:name": "svc", "ref:level": "debug"}
+	resolve := func(_ context.Context, key, value string) (string, error) {
+		if !strings.HasPrefix(value, "ref:") {
+			return value, nil
+		}
+
+		if val, ok := refs[value]; ok {
+			return val, nil
+		}
+
+		return "", errors.New("unknown reference")
+	}
+
+	values := map[string]string{"HOOK_NAME": "ref:name", "HOOK_HOST": "localhost"}
+	p := envs.NewParserOpts(envs.WithValueFunc(envs.FromMap(values)), envs.WithValueHook(resolve),
+		envs.WithValueHook(func(_ context.Context, key, value string) (string, error) {
+			return strings.ToUpper(value), nil
+		}))
+
+	var cfg Config
+	if err := p.ParseStruct(&cfg, "HOOK"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if want := (Config{Name: "SVC", Level: "DEBUG", Host: "LOCALHOST"}); cfg != want {
+		t.Errorf("got: %+v want: %+v", cfg, want)
+	}
+
+	if got := p.Resolutions()[0].Raw; got != "ref:name" {
+		t.Errorf("Resolutions()[0].Raw = %q, want the value before hooks", got)
+	}
+
+	values["HOOK_NAME"] = "ref:missing"
+	p = envs.NewParserOpts(envs.WithValueFunc(envs.FromMap(values)), envs.WithValueHook(resolve))
+	if err := p.ParseStruct(&cfg, "HOOK"); err == nil || !strings.Contains(err.Error(), "HOOK_NAME") {
+		t.Errorf("ParseStruct() error = %v, want an error naming HOOK_NAME", err)
+	}
+}
@@ -0,0 +1,100 @@
+package envs
+
+import (
+	"context"
+	"fmt"
+	r "reflect"
+	"sync"
+)
+
+// prefetch reads the keys of every field of the struct dest points to with m.concurrency workers,
+// the first failed read stops it
+func (m *Parser) prefetch(ctx context.Context, dest interface{}, prefix string) (map[string]fetched, error) {
+	t := r.TypeOf(dest)
+	for t != nil && t.Kind() == r.Pointer {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != r.Struct {
+		return nil, nil
+	}
+
+	keys := m.structKeys(t, prefix, prefix, "", map[r.Type]bool{}, nil)
+	values := make(map[string]fetched, len(keys))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+
+	queue := make(chan string)
+	for i := 0; i < min(m.concurrency, len(keys)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for key := range queue {
+				val, found, err := m.get(ctx, key)
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", key, err)
+					cancel()
+				}
+
+				values[key] = fetched{value: val, found: found}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			break
+		}
+
+		queue <- key
+	}
+
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return values, ctx.Err()
+}
+
+// structKeys appends the built keys ParseStruct reads for the fields of t under prefix, root is the prefix
+// ParseStruct started with. recursive types are left to ParseStruct to report
+func (m *Parser) structKeys(t r.Type, root, prefix, path string, visiting map[r.Type]bool, keys []string) []string {
+	for t.Kind() == r.Pointer {
+		t = t.Elem()
+	}
+
+	if visiting[t] {
+		return keys
+	}
+
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for _, sf := range m.structFields(t) {
+		fieldPath := joinKey(path, sf.name)
+		key := m.fieldKey(sf, root, prefix, fieldPath)
+		if !sf.opts.inline || !isNested(sf.typ) {
+			keys = append(keys, m.fieldKeys(sf, root, prefix, key)...)
+		}
+
+		if isNested(sf.typ) {
+			keys = m.structKeys(sf.typ, root, key, fieldPath, visiting, keys)
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,72 @@
+package envs
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+const redactedText = "***"
+
+// Redacted wraps a secret value so it can not leak through logging or encoding, every printed or encoded form
+// of it is `***` and the actual value is only available through Value.
+// fields of type Redacted are parsed like their underlying type.
+type Redacted[T any] struct {
+	value T
+}
+
+// NewRedacted wraps value
+func NewRedacted[T any](value T) Redacted[T] {
+	return Redacted[T]{value: value}
+}
+
+// Value returns the wrapped value
+func (s Redacted[T]) Value() T {
+	return s.value
+}
+
+func (s Redacted[T]) String() string {
+	return redactedText
+}
+
+func (s Redacted[T]) GoString() string {
+	return redactedText
+}
+
+// Format makes every fmt verb print `***`
+func (s Redacted[T]) Format(f fmt.State, _ rune) {
+	_, _ = io.WriteString(f, redactedText)
+}
+
+// LogValue keeps the value out of slog records
+func (s Redacted[T]) LogValue() slog.Value {
+	return slog.StringValue(redactedText)
+}
+
+func (s Redacted[T]) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redactedText + `"`), nil
+}
+
+func (s Redacted[T]) MarshalText() ([]byte, error) {
+	return []byte(redactedText), nil
+}
+
+// UnmarshalText parses text as T, the returned error never contains text
+func (s *Redacted[T]) UnmarshalText(text []byte) error {
+	value, err := parse[T](string(text))
+	if err != nil {
+		return redactError(err, fmt.Sprintf("%T", s.value))
+	}
+
+	s.value = value
+	return nil
+}
+
+// revealer is implemented by Redacted so exporters can reach the wrapped value
+type revealer interface {
+	reveal() any
+}
+
+func (s Redacted[T]) reveal() any {
+	return s.value
+}
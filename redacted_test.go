@@ -0,0 +1,55 @@
+package envs_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestRedacted(t *testing.T) {
+	type Config struct {
+		Password envs.Redacted[string] `env:"PASSWORD"`
+		Pin      envs.Redacted[int]    `env:"PIN"`
+	}
+
+	values := map[string]string{"REDACTED_PASSWORD": "hunter2", "REDACTED_PIN": "1234"}
+	p := envs.NewParserOpts(envs.WithValueFunc(func(key, def string) string {
+		if val, ok := values[key]; ok {
+			return val
+		}
+
+		return def
+	}))
+
+	cfg := Config{}
+	if err := p.ParseStruct(&cfg, "REDACTED"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if cfg.Password.Value() != "hunter2" || cfg.Pin.Value() != 1234 {
+		t.Errorf("Value() = %v, %v want %v, %v", cfg.Password.Value(), cfg.Pin.Value(), "hunter2", 1234)
+	}
+
+	encoded, _ := json.Marshal(cfg)
+	logged := &bytes.Buffer{}
+	slog.New(slog.NewTextHandler(logged, nil)).Info("config", "password", cfg.Password)
+
+	for _, out := range []string{
+		fmt.Sprintf("%v", cfg), fmt.Sprintf("%+v", cfg), fmt.Sprintf("%#v", cfg), fmt.Sprintf("%s", cfg.Password),
+		fmt.Sprintf("%d", cfg.Pin), string(encoded), logged.String(),
+	} {
+		if strings.Contains(out, "hunter2") || strings.Contains(out, "1234") {
+			t.Errorf("output leaks the secret: %s", out)
+		}
+	}
+
+	values["REDACTED_PIN"] = "pin-9876"
+	if err := p.ParseStruct(&cfg, "REDACTED"); err == nil || strings.Contains(err.Error(), "pin-9876") {
+		t.Errorf("ParseStruct() error = %v, want an error without the value", err)
+	}
+}
@@ -0,0 +1,206 @@
+package envs
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedisSource reads values from Redis with GET, or with HGET from Hash when it is set, keys are looked up under
+// Prefix. a single connection is kept open and dialed again after failures
+type RedisSource struct {
+	// Address is the host:port of the Redis server
+	Address string
+	// Username and Password are sent with AUTH when Password is not empty
+	Username string
+	Password string
+	// DB is selected after connecting when it is not zero
+	DB int
+	// Hash makes lookups read fields of this hash instead of plain keys
+	Hash string
+	// Prefix is prepended to every key or hash field
+	Prefix string
+	// Timeout limits dialing and every command, it is 5 seconds when zero, deadlines of ctx take precedence
+	Timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// NewRedisSource creates a RedisSource reading plain keys from the Redis server at address
+func NewRedisSource(address string) *RedisSource {
+	return &RedisSource{Address: address}
+}
+
+// Lookup reads Prefix+key with GET or HGET, missing keys are not found
+func (s *RedisSource) Lookup(ctx context.Context, key string) (string, bool, error) {
+	args := []string{"GET", s.Prefix + key}
+	if s.Hash != "" {
+		args = []string{"HGET", s.Hash, s.Prefix + key}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	val, found, err := s.do(ctx, args...)
+	if err != nil {
+		return "", false, fmt.Errorf("redis: %w", err)
+	}
+
+	return val, found, nil
+}
+
+// Close closes the open connection
+func (s *RedisSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.reset()
+}
+
+// do sends a command, connecting first when needed, a nil reply is not found
+func (s *RedisSource) do(ctx context.Context, args ...string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+
+	if s.conn == nil {
+		if err := s.connect(ctx); err != nil {
+			return "", false, err
+		}
+	}
+
+	val, found, err := s.command(ctx, args...)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		_ = s.reset()
+	}
+
+	return val, found, err
+}
+
+func (s *RedisSource) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: s.timeout()}
+	conn, err := dialer.DialContext(ctx, "tcp", s.Address)
+	if err != nil {
+		return err
+	}
+
+	s.conn, s.rd = conn, bufio.NewReader(conn)
+	if s.Password != "" {
+		args := []string{"AUTH", s.Password}
+		if s.Username != "" {
+			args = []string{"AUTH", s.Username, s.Password}
+		}
+
+		if _, _, err := s.command(ctx, args...); err != nil {
+			_ = s.reset()
+			return err
+		}
+	}
+
+	if s.DB != 0 {
+		if _, _, err := s.command(ctx, "SELECT", strconv.Itoa(s.DB)); err != nil {
+			_ = s.reset()
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *RedisSource) reset() error {
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn, s.rd = nil, nil
+
+	return err
+}
+
+func (s *RedisSource) timeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+
+	return 5 * time.Second
+}
+
+// command writes args as a RESP array and reads the reply
+func (s *RedisSource) command(ctx context.Context, args ...string) (string, bool, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(s.timeout())
+	}
+
+	if err := s.conn.SetDeadline(deadline); err != nil {
+		return "", false, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if _, err := io.WriteString(s.conn, b.String()); err != nil {
+		return "", false, err
+	}
+
+	return readRedisReply(s.rd)
+}
+
+// redisError is an error reply of the server, the connection stays usable after it
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// readRedisReply reads a simple string, error, integer or bulk string reply
+func readRedisReply(rd *bufio.Reader) (string, bool, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return "", false, err
+	}
+
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return "", false, errors.New("empty reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return line[1:], true, nil
+	case '-':
+		return "", false, redisError(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", false, fmt.Errorf("invalid bulk length %q", line[1:])
+		}
+
+		if n < 0 {
+			return "", false, nil
+		}
+
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return "", false, err
+		}
+
+		return string(buf[:n]), true, nil
+	default:
+		return "", false, fmt.Errorf("unsupported reply %q", line)
+	}
+}
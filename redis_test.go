@@ -0,0 +1,129 @@
+package envs_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+// serveRedis answers AUTH, SELECT, GET and HGET on conn from keys and the hashes in hashes
+func serveRedis(conn net.Conn, password string, keys map[string]string, hashes map[string]map[string]string) {
+	defer conn.Close()
+
+	rd := bufio.NewReader(conn)
+	authed := password == ""
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := make([]string, n)
+		for i := range args {
+			line, _ = rd.ReadString('\n')
+			size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+			buf := make([]byte, size+2)
+			if _, err := io.ReadFull(rd, buf); err != nil {
+				return
+			}
+
+			args[i] = string(buf[:size])
+		}
+
+		var (
+			val   string
+			found bool
+		)
+
+		switch {
+		case args[0] == "AUTH" && args[len(args)-1] == password:
+			authed = true
+			fmt.Fprint(conn, "+OK\r\n")
+			continue
+		case !authed:
+			fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+			continue
+		case args[0] == "SELECT":
+			fmt.Fprint(conn, "+OK\r\n")
+			continue
+		case args[0] == "GET":
+			val, found = keys[args[1]]
+		case args[0] == "HGET":
+			val, found = hashes[args[1]][args[2]]
+		}
+
+		if !found {
+			fmt.Fprint(conn, "$-1\r\n")
+			continue
+		}
+
+		fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(val), val)
+	}
+}
+
+func TestRedisSource(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+
+	keys := map[string]string{"cfg:APP_PORT": "9090", "cfg:APP_NAME": ""}
+	hashes := map[string]map[string]string{"features": {"APP_PORT": "7070"}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveRedis(conn, "secret", keys, hashes)
+		}
+	}()
+
+	type Config struct {
+		Name string `env:"NAME,default=svc"`
+		Port int    `env:"PORT"`
+		Host string `env:"HOST,default=localhost"`
+	}
+
+	source := envs.NewRedisSource(ln.Addr().String())
+	source.Password = "secret"
+	source.DB = 2
+	source.Prefix = "cfg:"
+	defer source.Close()
+
+	var got Config
+	if err := envs.NewParserOpts(envs.WithSource(source)).ParseStruct(&got, "APP"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if want := (Config{Port: 9090, Host: "localhost"}); got != want {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+
+	hash := envs.NewRedisSource(ln.Addr().String())
+	hash.Password = "secret"
+	hash.Hash = "features"
+	defer hash.Close()
+
+	if val, found, err := hash.Lookup(context.Background(), "APP_PORT"); val != "7070" || !found || err != nil {
+		t.Errorf("Lookup(APP_PORT) = %q, %v, %v, want 7070", val, found, err)
+	}
+
+	anonymous := envs.NewRedisSource(ln.Addr().String())
+	defer anonymous.Close()
+
+	if _, _, err := anonymous.Lookup(context.Background(), "APP_PORT"); err == nil ||
+		!strings.Contains(err.Error(), "NOAUTH") {
+		t.Errorf("Lookup() error = %v, want NOAUTH", err)
+	}
+}
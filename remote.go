@@ -0,0 +1,50 @@
+package envs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxResponseSize limits how much of a response body remote sources read
+const maxResponseSize = 10 << 20
+
+// fetch sends a request built from method, url, header and body with client and returns the response body and
+// headers, found is false for 404 responses and other non 2xx responses are reported as errors
+func fetch(ctx context.Context, client *http.Client, method, url string, header http.Header, body io.Reader) (
+	data []byte, resHeader http.Header, found bool, err error,
+) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	defer res.Body.Close()
+
+	data, err = io.ReadAll(io.LimitReader(res.Body, maxResponseSize))
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, res.Header, false, nil
+	case res.StatusCode < 200 || res.StatusCode > 299:
+		return nil, res.Header, false, fmt.Errorf("%s %s: %s", method, req.URL.Redacted(), res.Status)
+	}
+
+	return data, res.Header, true, nil
+}
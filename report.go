@@ -0,0 +1,48 @@
+package envs
+
+// Resolution tells where the value of a single field came from
+type Resolution struct {
+	// FieldPath is the dotted path of the field in the destination struct, like Server.Port
+	FieldPath string
+	// Key is the key the value was looked up with
+	Key string
+	// Source is the name of the source that provided the value, empty when the key was not found and
+	// `preset` for values kept by WithPreserveValues
+	Source string
+	// UsedDefault is true when the value came from the struct tag default
+	UsedDefault bool
+	// Raw is the value before parsing
+	Raw string
+}
+
+// Resolutions returns the resolutions recorded by the last ParseStruct call, one for every field the parser
+// looked up, in struct field order.
+func (m *Parser) Resolutions() []Resolution {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make([]Resolution, len(m.resolutions))
+	copy(res, m.resolutions)
+
+	return res
+}
+
+func (m *Parser) setResolutions(resolutions []Resolution) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.resolutions = resolutions
+}
+
+func (m *Parser) resolution(path, key, raw string, found, usedDefault bool) Resolution {
+	res := Resolution{FieldPath: path, Key: key, UsedDefault: usedDefault, Raw: raw}
+
+	switch {
+	case usedDefault:
+		res.Source = defaultSource
+	case found:
+		res.Source = m.sourceName
+	}
+
+	return res
+}
@@ -0,0 +1,121 @@
+package envs_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestParser_Resolutions(t *testing.T) {
+	type Config struct {
+		Name   string `env:"NAME"`
+		Port   int    `env:"PORT,default=8080"`
+		Debug  bool   `env:"DEBUG"`
+		Server struct {
+			Host string `env:"HOST"`
+		} `env:"SERVER"`
+	}
+
+	values := map[string]string{"REPORT_NAME": "svc", "REPORT_SERVER_HOST": "localhost"}
+	p := envs.NewParserOpts(envs.WithValueFunc(func(key, def string) string {
+		if val, ok := values[key]; ok {
+			return val
+		}
+
+		return def
+	}))
+
+	cfg := Config{}
+	if err := p.ParseStruct(&cfg, "REPORT"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	want := []envs.Resolution{
+		{FieldPath: "Name", Key: "REPORT_NAME", Source: "custom", Raw: "svc"},
+		{FieldPath: "Port", Key: "REPORT_PORT", Source: "default", UsedDefault: true, Raw: "8080"},
+		{FieldPath: "Debug", Key: "REPORT_DEBUG"},
+		{FieldPath: "Server.Host", Key: "REPORT_SERVER_HOST", Source: "custom", Raw: "localhost"},
+	}
+
+	if got := p.Resolutions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Resolutions() = %v, want %v", got, want)
+	}
+}
+
+func TestParser_secretFields(t *testing.T) {
+	values := map[string]string{"SECRET_TOKEN": "super-secret-1234", "SECRET_PIN": "not-a-number-9876"}
+	p := envs.NewParserOpts(envs.WithValueFunc(func(key, def string) string {
+		if val, ok := values[key]; ok {
+			return val
+		}
+
+		return def
+	}))
+
+	cfg := struct {
+		Token string `env:"TOKEN,secret"`
+	}{}
+
+	if err := p.ParseStruct(&cfg, "SECRET"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if got := p.Resolutions()[0].Raw; got != "****1234" {
+		t.Errorf("Resolutions() raw = %v, want %v", got, "****1234")
+	}
+
+	bad := struct {
+		Pin int `env:"PIN,secret"`
+	}{}
+
+	err := p.ParseStruct(&bad, "SECRET")
+	if err == nil {
+		t.Fatalf("ParseStruct() expected error")
+	}
+
+	if strings.Contains(err.Error(), "not-a-number") || !strings.Contains(err.Error(), "SECRET_PIN") {
+		t.Errorf("ParseStruct() error = %v, want the key without the secret value", err)
+	}
+}
+
+func TestParser_secretFieldItems(t *testing.T) {
+	values := envs.FromMap(map[string]string{
+		"SECRET_PINS":   "1,hunter2pass",
+		"SECRET_TOKENS": "api:1,db:sup3rs3cret",
+		"SECRET_KEYS":   "hunter2key:1",
+		"SECRET_UMASK":  "0o9hunter2",
+	})
+	p := envs.NewParserOpts(envs.WithValueFunc(values))
+
+	tests := map[string]interface{}{
+		"slice": &struct {
+			Pins []int `env:"PINS,secret"`
+		}{},
+		"map value": &struct {
+			Tokens map[string]int `env:"TOKENS,secret"`
+		}{},
+		"map key": &struct {
+			Keys map[int]int `env:"KEYS,secret"`
+		}{},
+		"octal": &struct {
+			Umask int `env:"UMASK,secret,octal"`
+		}{},
+	}
+
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := p.ParseStruct(cfg, "SECRET")
+			if err == nil {
+				t.Fatalf("ParseStruct() expected error")
+			}
+
+			for _, secret := range []string{"hunter2", "sup3rs3cret"} {
+				if strings.Contains(err.Error(), secret) {
+					t.Errorf("ParseStruct() error = %v, contains a part of the secret value", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package envs
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy tells WithRetry how often and how long to wait before trying a failed lookup again,
+// zero fields use the defaults
+type RetryPolicy struct {
+	// Attempts is the number of lookups including the first one, 3 by default
+	Attempts int
+	// Delay is the wait before the first retry, 100ms by default
+	Delay time.Duration
+	// MaxDelay caps the wait between retries, 5s by default
+	MaxDelay time.Duration
+	// Multiplier grows the wait after every retry, 2 by default
+	Multiplier float64
+	// Jitter is the fraction of the wait that is randomized, like 0.2 for ±20%, 0.2 by default
+	Jitter float64
+	// Retryable reports whether an error is transient, every error but context cancellation is by default
+	Retryable func(err error) bool
+}
+
+// retrySource is the Source returned by WithRetry
+type retrySource struct {
+	source Source
+	policy RetryPolicy
+}
+
+// WithRetry wraps source so failed lookups are tried again with exponential backoff and jitter,
+// so a single blip of a secret store does not fail startup
+func WithRetry(source Source, policy RetryPolicy) Source {
+	if policy.Attempts <= 0 {
+		policy.Attempts = 3
+	}
+
+	if policy.Delay <= 0 {
+		policy.Delay = 100 * time.Millisecond
+	}
+
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = 5 * time.Second
+	}
+
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 2
+	}
+
+	if policy.Jitter <= 0 || policy.Jitter > 1 {
+		policy.Jitter = 0.2
+	}
+
+	if policy.Retryable == nil {
+		policy.Retryable = func(err error) bool {
+			return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+		}
+	}
+
+	return &retrySource{source: source, policy: policy}
+}
+
+func (s *retrySource) Lookup(ctx context.Context, key string) (string, bool, error) {
+	delay := s.policy.Delay
+	for attempt := 1; ; attempt++ {
+		val, found, err := s.source.Lookup(ctx, key)
+		if err == nil || attempt == s.policy.Attempts || !s.policy.Retryable(err) {
+			return val, found, err
+		}
+
+		// #nosec G404 -- jitter does not need a secure random source
+		wait := time.Duration(float64(delay) * (1 + s.policy.Jitter*(2*rand.Float64()-1)))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", false, err
+		case <-timer.C:
+		}
+
+		delay = min(time.Duration(float64(delay)*s.policy.Multiplier), s.policy.MaxDelay)
+	}
+}
+
+// Wait reports changes of the wrapped source when it is a Waiter, it waits for ctx to be done otherwise
+func (s *retrySource) Wait(ctx context.Context) error {
+	return waitFor(ctx, s.source)
+}
@@ -0,0 +1,61 @@
+package envs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func TestWithRetry(t *testing.T) {
+	errBlip := errors.New("connection reset")
+	errDenied := errors.New("permission denied")
+
+	var calls int
+	flaky := envs.SourceFunc(func(_ context.Context, key string) (string, bool, error) {
+		calls++
+		switch {
+		case key == "DENIED":
+			return "", false, errDenied
+		case key == "DOWN" || calls < 3:
+			return "", false, errBlip
+		}
+
+		return "value", true, nil
+	})
+
+	source := envs.WithRetry(flaky, envs.RetryPolicy{
+		Attempts:  4,
+		Delay:     time.Millisecond,
+		Retryable: func(err error) bool { return !errors.Is(err, errDenied) },
+	})
+
+	if val, found, err := source.Lookup(context.Background(), "KEY"); val != "value" || !found || err != nil {
+		t.Errorf("Lookup() = %q, %v, %v, want value after retries", val, found, err)
+	}
+
+	if calls != 3 {
+		t.Errorf("source called %d times, want 3", calls)
+	}
+
+	calls = 0
+	if _, _, err := source.Lookup(context.Background(), "DOWN"); !errors.Is(err, errBlip) || calls != 4 {
+		t.Errorf("Lookup(DOWN) = %v after %d calls, want %v after 4", err, calls, errBlip)
+	}
+
+	calls = 0
+	if _, _, err := source.Lookup(context.Background(), "DENIED"); !errors.Is(err, errDenied) || calls != 1 {
+		t.Errorf("Lookup(DENIED) = %v after %d calls, want %v after 1", err, calls, errDenied)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	calls = 0
+	slow := envs.WithRetry(flaky, envs.RetryPolicy{Attempts: 10, Delay: time.Hour})
+	if _, _, err := slow.Lookup(ctx, "DOWN"); !errors.Is(err, errBlip) || calls != 1 {
+		t.Errorf("Lookup(DOWN) = %v after %d calls, want to give up once ctx is done", err, calls)
+	}
+}
@@ -0,0 +1,270 @@
+package envs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	r "reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// patterns values of each kind have to match, values are always strings since they describe environment variables
+const (
+	intPattern      = `^[-+]?[0-9]+$`
+	uintPattern     = `^\+?[0-9]+$`
+	floatPattern    = `^[-+]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][-+]?[0-9]+)?$`
+	boolPattern     = `^(1|t|T|TRUE|true|True|0|f|F|FALSE|false|False)$`
+	fileModePattern = `^(0[oO]?)?[0-7]+$`
+	durationPattern = `^[-+]?(0|([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
+)
+
+type schemaDocument struct {
+	Schema     string                    `json:"$schema"`
+	Type       string                    `json:"type"`
+	Properties map[string]schemaProperty `json:"properties"`
+	Required   []string                  `json:"required,omitempty"`
+}
+
+type schemaProperty struct {
+	Type        string   `json:"type"`
+	Format      string   `json:"format,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Examples    []string `json:"examples,omitempty"`
+	WriteOnly   bool     `json:"writeOnly,omitempty"`
+	GoType      string   `json:"x-go-type"`
+}
+
+// Schema returns a JSON Schema describing the flattened keys of cfg, every key is a string property
+// constrained by a pattern or format matching the field type, fields tagged as required are listed as required.
+func Schema(cfg interface{}) ([]byte, error) {
+	return NewParser(nil, nil).Schema(cfg)
+}
+
+// Schema returns a JSON Schema describing the flattened keys of cfg using the parser prefix and key function
+func (m *Parser) Schema(cfg interface{}) ([]byte, error) {
+	doc := schemaDocument{Schema: jsonSchemaDraft, Type: "object", Properties: map[string]schemaProperty{}}
+
+	err := m.walk(r.ValueOf(cfg), m.prefix, "", func(f field) error {
+		prop := schemaFor(f.Type)
+		if f.Tag.octal {
+			prop.Format, prop.Pattern = "file-mode", fileModePattern
+		}
+
+		prop.Description = f.Tag.desc
+		prop.Default = f.Tag.def
+		prop.WriteOnly = f.Tag.secret
+		if f.Tag.example != "" {
+			prop.Examples = []string{f.Tag.example}
+		}
+
+		doc.Properties[f.Key] = prop
+		if m.required(f.Tag) {
+			doc.Required = append(doc.Required, f.Key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// schemaFor describes the string values that can be parsed as t
+func schemaFor(t r.Type) schemaProperty {
+	prop := schemaProperty{Type: "string", GoType: t.String()}
+	for t.Kind() == r.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		prop.Format = "date-time"
+		return prop
+	case durationType:
+		prop.Format = "duration"
+		prop.Pattern = durationPattern
+		return prop
+	case fileModeType:
+		prop.Format = "file-mode"
+		prop.Pattern = fileModePattern
+		return prop
+	case urlType.Elem():
+		prop.Format = "uri"
+		return prop
+	}
+
+	if r.PointerTo(t).Implements(textUnmarshalerType) {
+		return prop
+	}
+
+	switch t.Kind() {
+	case r.Int, r.Int8, r.Int16, r.Int32, r.Int64:
+		prop.Format = "int" + strconv.Itoa(t.Bits())
+		prop.Pattern = intPattern
+	case r.Uint, r.Uint8, r.Uint16, r.Uint32, r.Uint64, r.Uintptr:
+		prop.Format = "uint" + strconv.Itoa(t.Bits())
+		prop.Pattern = uintPattern
+	case r.Float32, r.Float64:
+		prop.Format = "float" + strconv.Itoa(t.Bits())
+		prop.Pattern = floatPattern
+	case r.Bool:
+		prop.Format = "boolean"
+		prop.Pattern = boolPattern
+	case r.Slice, r.Array:
+		prop.Format = "list"
+	case r.Map:
+		prop.Format = "map"
+	}
+
+	return prop
+}
+
+// ViolationKind tells why a value does not match a schema
+type ViolationKind int
+
+const (
+	// ViolationMissing is reported for required keys without a value
+	ViolationMissing ViolationKind = iota + 1
+	// ViolationInvalid is reported for values that do not match the pattern or format of their key
+	ViolationInvalid
+	// ViolationUnknown is reported for keys that are not described by the schema
+	ViolationUnknown
+)
+
+func (k ViolationKind) String() string {
+	switch k {
+	case ViolationMissing:
+		return "missing"
+	case ViolationInvalid:
+		return "invalid"
+	case ViolationUnknown:
+		return "unknown"
+	default:
+		return "unknown kind"
+	}
+}
+
+// Violation is a single problem found by ValidateSchema
+type Violation struct {
+	Key  string
+	Kind ViolationKind
+	// Err is set for ViolationInvalid, it never contains values of writeOnly (secret) keys
+	Err error
+}
+
+func (v Violation) String() string {
+	if v.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", v.Key, v.Kind, v.Err)
+	}
+
+	return fmt.Sprintf("%s: %s", v.Key, v.Kind)
+}
+
+// ValidateSchema checks values against a schema generated by Schema, empty values count as unset.
+// keys starting with prefix followed by `_` that the schema does not describe are reported as unknown,
+// every key of values is checked when prefix is empty. violations are sorted by key.
+func ValidateSchema(schema []byte, values map[string]string, prefix string) ([]Violation, error) {
+	var doc schemaDocument
+	if err := json.Unmarshal(schema, &doc); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	required := make(map[string]bool, len(doc.Required))
+	for _, key := range doc.Required {
+		required[key] = true
+	}
+
+	var violations []Violation
+	for key, prop := range doc.Properties {
+		val := values[key]
+		if val == "" {
+			if required[key] {
+				violations = append(violations, Violation{Key: key, Kind: ViolationMissing})
+			}
+
+			continue
+		}
+
+		if err := prop.validate(val); err != nil {
+			if prop.WriteOnly {
+				err = redactError(err, key)
+			}
+
+			violations = append(violations, Violation{Key: key, Kind: ViolationInvalid, Err: err})
+		}
+	}
+
+	for key := range values {
+		if _, ok := doc.Properties[key]; ok || (prefix != "" && !strings.HasPrefix(key, prefix+"_")) {
+			continue
+		}
+
+		violations = append(violations, Violation{Key: key, Kind: ViolationUnknown})
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].Key < violations[j].Key
+	})
+
+	return violations, nil
+}
+
+// validate checks val against the pattern and format of the property
+func (p schemaProperty) validate(val string) error {
+	if p.Pattern != "" {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", p.Pattern, err)
+		}
+
+		if !re.MatchString(val) {
+			return fmt.Errorf("%q does not match %s", val, p.Pattern)
+		}
+	}
+
+	var err error
+	switch {
+	case p.Format == "date-time":
+		_, err = parseTime(val)
+		if err != nil {
+			err = errors.New("not a supported time format")
+		}
+	case p.Format == "duration":
+		_, err = time.ParseDuration(val)
+	case p.Format == "file-mode":
+		_, err = parseOctal(val)
+	case p.Format == "uri":
+		_, err = url.Parse(val)
+	case p.Format == "boolean":
+		_, err = strconv.ParseBool(val)
+	case strings.HasPrefix(p.Format, "uint"):
+		_, err = strconv.ParseUint(strings.TrimPrefix(val, "+"), 10, formatBits(p.Format, "uint"))
+	case strings.HasPrefix(p.Format, "int"):
+		_, err = strconv.ParseInt(val, 10, formatBits(p.Format, "int"))
+	case strings.HasPrefix(p.Format, "float"):
+		_, err = strconv.ParseFloat(val, formatBits(p.Format, "float"))
+	}
+
+	return err
+}
+
+// formatBits returns the bit size in formats like int32, 64 is used when it is missing
+func formatBits(format, kind string) int {
+	bits, err := strconv.Atoi(strings.TrimPrefix(format, kind))
+	if err != nil {
+		return 64
+	}
+
+	return bits
+}
@@ -0,0 +1,144 @@
+package envs_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func TestSchema(t *testing.T) {
+	type Config struct {
+		Port    int           `env:"PORT,default=8080,desc=port to listen on"`
+		Token   string        `env:"TOKEN,required,secret,example=tok_1"`
+		Debug   bool          `env:"DEBUG"`
+		Timeout time.Duration `env:"TIMEOUT"`
+		Hosts   []string      `env:"HOSTS"`
+	}
+
+	out, err := envs.NewParserOpts(envs.WithPrefix("APP")).Schema(Config{})
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	var doc struct {
+		Schema     string `json:"$schema"`
+		Properties map[string]struct {
+			Type        string   `json:"type"`
+			Format      string   `json:"format"`
+			Pattern     string   `json:"pattern"`
+			Description string   `json:"description"`
+			Default     string   `json:"default"`
+			Examples    []string `json:"examples"`
+			WriteOnly   bool     `json:"writeOnly"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+
+	if err = json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("Schema() returned invalid json: %v", err)
+	}
+
+	if len(doc.Properties) != 5 || doc.Schema == "" {
+		t.Fatalf("Schema() = %s", out)
+	}
+
+	if !reflect.DeepEqual(doc.Required, []string{"APP_TOKEN"}) {
+		t.Errorf("required = %v, want %v", doc.Required, []string{"APP_TOKEN"})
+	}
+
+	port := doc.Properties["APP_PORT"]
+	if port.Type != "string" || port.Default != "8080" || port.Description != "port to listen on" {
+		t.Errorf("APP_PORT = %+v", port)
+	}
+
+	if token := doc.Properties["APP_TOKEN"]; !token.WriteOnly || !reflect.DeepEqual(token.Examples, []string{"tok_1"}) {
+		t.Errorf("APP_TOKEN = %+v", token)
+	}
+
+	patterns := map[string][2]string{
+		"APP_PORT":    {"-42", "4.2"},
+		"APP_DEBUG":   {"true", "yes"},
+		"APP_TIMEOUT": {"1h2m3.5s", "10"},
+	}
+
+	for key, values := range patterns {
+		re := regexp.MustCompile(doc.Properties[key].Pattern)
+		if !re.MatchString(values[0]) || re.MatchString(values[1]) {
+			t.Errorf("%s pattern %s should match %s and not %s", key, re, values[0], values[1])
+		}
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	type Config struct {
+		Port    int8          `env:"PORT,default=80"`
+		Token   string        `env:"TOKEN,required,secret"`
+		Name    string        `env:"NAME,required"`
+		Timeout time.Duration `env:"TIMEOUT"`
+		Started time.Time     `env:"STARTED"`
+		Umask   int           `env:"UMASK,octal"`
+	}
+
+	schema, err := envs.NewParserOpts(envs.WithPrefix("APP")).Schema(Config{})
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	values := map[string]string{
+		"APP_PORT":    "300",
+		"APP_NAME":    "svc",
+		"APP_TIMEOUT": "5 seconds",
+		"APP_STARTED": "2023-01-02T15:04:05Z",
+		"APP_UMASK":   "0o22",
+		"APP_EXTRA":   "1",
+		"HOME":        "/root",
+	}
+
+	got, err := envs.ValidateSchema(schema, values, "APP")
+	if err != nil {
+		t.Fatalf("ValidateSchema() error = %v", err)
+	}
+
+	want := []struct {
+		key  string
+		kind envs.ViolationKind
+	}{
+		{"APP_EXTRA", envs.ViolationUnknown},
+		{"APP_PORT", envs.ViolationInvalid},
+		{"APP_TIMEOUT", envs.ViolationInvalid},
+		{"APP_TOKEN", envs.ViolationMissing},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("ValidateSchema() = %v, want %v", got, want)
+	}
+
+	for i, w := range want {
+		if got[i].Key != w.key || got[i].Kind != w.kind {
+			t.Errorf("violation[%d] = %v, want %s: %s", i, got[i], w.key, w.kind)
+		}
+	}
+
+	values = map[string]string{"APP_NAME": "svc", "APP_TOKEN": "secret-token-value", "APP_PORT": "x"}
+	if got, _ = envs.ValidateSchema(schema, values, "APP"); len(got) != 1 {
+		t.Fatalf("ValidateSchema() = %v, want a single violation", got)
+	}
+
+	values = map[string]string{"APP_NAME": "svc", "APP_TOKEN": "secret-token-value", "APP_UMASK": "089"}
+	if got, _ = envs.ValidateSchema(schema, values, "APP"); len(got) != 1 || got[0].Key != "APP_UMASK" {
+		t.Fatalf("ValidateSchema() = %v, want APP_UMASK as invalid octal", got)
+	}
+
+	values = map[string]string{"APP_NAME": "svc", "APP_TOKEN": "secret", "HOME": "/root"}
+	if got, _ = envs.ValidateSchema(schema, values, ""); len(got) != 1 || got[0].Kind != envs.ViolationUnknown {
+		t.Errorf("ValidateSchema() without prefix = %v, want HOME as unknown", got)
+	}
+
+	if _, err = envs.ValidateSchema([]byte("{"), values, ""); err == nil {
+		t.Error("ValidateSchema() expected an error for an invalid schema")
+	}
+}
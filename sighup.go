@@ -0,0 +1,40 @@
+//go:build !js && !wasip1 && !plan9
+
+package envs
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// OnSIGHUP calls reload every time the process receives SIGHUP, the classic way of telling a daemon to read its
+// configuration again, errors are reported to the package logger as EventReloadError. reload can parse the
+// configuration again or call Watcher.Reload. the returned stop function stops listening for the signal
+func OnSIGHUP(reload func() error) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-signals:
+				if err := reload(); err != nil {
+					emit(Event{Kind: EventReloadError, Err: err})
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build js || wasip1 || plan9
+
+package envs
+
+// OnSIGHUP does nothing on platforms without SIGHUP like js and plan9, the reload function is never called.
+// the returned stop function is safe to call
+func OnSIGHUP(_ func() error) (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,59 @@
+//go:build !js && !wasip1 && !plan9
+
+package envs_test
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func TestOnSIGHUP(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGHUP is not delivered on windows")
+	}
+
+	events := make(chan envs.Event, 1)
+	envs.SetLogger(func(e envs.Event) { events <- e })
+	t.Cleanup(func() { envs.SetLogger(nil) })
+
+	errReload := errors.New("invalid configuration")
+	reloads := make(chan struct{}, 1)
+	stop := envs.OnSIGHUP(func() error {
+		reloads <- struct{}{}
+		return errReload
+	})
+	defer stop()
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("FindProcess() error = %v", err)
+	}
+
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		t.Fatalf("Signal() error = %v", err)
+	}
+
+	select {
+	case <-reloads:
+	case <-time.After(time.Second):
+		t.Fatal("reload was not called after SIGHUP")
+	}
+
+	select {
+	case e := <-events:
+		if e.Kind != envs.EventReloadError || !errors.Is(e.Err, errReload) {
+			t.Errorf("logger got %+v, want a reload error", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the reload error was not reported")
+	}
+
+	stop()
+	stop()
+}
@@ -0,0 +1,94 @@
+package envs
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// Source is a store of values like a file, a key value store or a secret manager, Lookup reports whether key is set
+// so an empty value is told apart from a missing one, it should give up once ctx is done
+type Source interface {
+	Lookup(ctx context.Context, key string) (value string, found bool, err error)
+}
+
+// SourceFunc adapts a function to the Source interface
+type SourceFunc func(ctx context.Context, key string) (string, bool, error)
+
+// Lookup calls f
+func (f SourceFunc) Lookup(ctx context.Context, key string) (string, bool, error) {
+	return f(ctx, key)
+}
+
+// EnvSource reads the process environment with os.LookupEnv
+var EnvSource Source = SourceFunc(func(_ context.Context, key string) (string, bool, error) {
+	val, ok := os.LookupEnv(key)
+	return val, ok, nil
+})
+
+// ValueFuncSource adapts a ValueFunc to the Source interface, empty values count as missing
+func ValueFuncSource(valueFunc ValueFunc) Source {
+	return SourceFunc(func(_ context.Context, key string) (string, bool, error) {
+		val := valueFunc(key, "")
+		return val, val != "", nil
+	})
+}
+
+// SourceValueFunc adapts a Source to a ValueFunc for NewParser and NewGetter, def is returned for keys
+// that are not found and for failed lookups
+func SourceValueFunc(source Source) ValueFunc {
+	return func(key, def string) string {
+		val, found, err := source.Lookup(context.Background(), key)
+		if err != nil || !found {
+			return def
+		}
+
+		return val
+	}
+}
+
+// FromReader reads KEY=VALUE lines in the .env format from r and returns a ValueFunc serving them,
+// so files, stdin or embedded assets can be parsed without touching the process environment
+func FromReader(r io.Reader) (ValueFunc, error) {
+	values, err := ReadDotEnv(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromMap(values), nil
+}
+
+// FromMap returns a ValueFunc that reads from a copy of values, so tests can drive ParseStruct from in memory data
+// instead of calling os.Setenv, which can not be used with parallel tests. empty values count as missing
+func FromMap(values map[string]string) ValueFunc {
+	snapshot := make(map[string]string, len(values))
+	for k, v := range values {
+		snapshot[k] = v
+	}
+
+	return func(key, def string) string {
+		if val, ok := snapshot[key]; ok && val != "" {
+			return val
+		}
+
+		return def
+	}
+}
+
+// Sources returns a ValueFunc that consults sources in order and returns the first non-empty value, like the
+// process environment, then a .env file, then a remote store, the default is used when none of them has the key
+func Sources(sources ...ValueFunc) ValueFunc {
+	return func(key, def string) string {
+		for _, source := range sources {
+			if source == nil {
+				continue
+			}
+
+			if val := source(key, ""); val != "" {
+				return val
+			}
+		}
+
+		return def
+	}
+}
@@ -0,0 +1,123 @@
+package envs_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/OZahed/envs"
+)
+
+func TestFromReader(t *testing.T) {
+	type Config struct {
+		Name string `env:"NAME"`
+		Port int    `env:"PORT,8080"`
+		Host string `env:"HOST,localhost"`
+	}
+
+	valueFunc, err := envs.FromReader(strings.NewReader("# config\nSRC_NAME=svc\nSRC_PORT=\"9090\"\nSRC_HOST=\n"))
+	if err != nil {
+		t.Fatalf("FromReader() error = %v", err)
+	}
+
+	var got Config
+	if err := envs.NewParser(nil, valueFunc).ParseStruct(&got, "SRC"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	want := Config{Name: "svc", Port: 9090, Host: "localhost"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+
+	if _, err := envs.FromReader(strings.NewReader("not a pair\n")); err == nil {
+		t.Error("FromReader() expected an error for a malformed line")
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Name string `env:"NAME"`
+		Port int    `env:"PORT,8080"`
+	}
+
+	values := map[string]string{"MAP_NAME": "svc"}
+	valueFunc := envs.FromMap(values)
+	values["MAP_PORT"] = "9090"
+
+	var got Config
+	if err := envs.NewParser(nil, valueFunc).ParseStruct(&got, "MAP"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	want := Config{Name: "svc", Port: 8080}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSources(t *testing.T) {
+	t.Setenv("LAYER_NAME", "from-env")
+
+	type Config struct {
+		Name  string `env:"NAME"`
+		Port  int    `env:"PORT"`
+		Host  string `env:"HOST"`
+		Level string `env:"LEVEL,info"`
+	}
+
+	dotEnv, err := envs.FromReader(strings.NewReader("LAYER_NAME=from-file\nLAYER_PORT=9090\n"))
+	if err != nil {
+		t.Fatalf("FromReader() error = %v", err)
+	}
+
+	remote := envs.FromMap(map[string]string{"LAYER_PORT": "7070", "LAYER_HOST": "remote"})
+
+	var got Config
+	parser := envs.NewParser(nil, envs.Sources(envs.DefaultGetFunc, dotEnv, nil, remote))
+	if err := parser.ParseStruct(&got, "LAYER"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	want := Config{Name: "from-env", Port: 9090, Host: "remote", Level: "info"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSourceAdapters(t *testing.T) {
+	t.Setenv("ADAPTER_EMPTY", "")
+
+	source := envs.ValueFuncSource(envs.FromMap(map[string]string{"ADAPTER_NAME": "svc", "ADAPTER_BLANK": ""}))
+	if val, found, err := source.Lookup(context.Background(), "ADAPTER_NAME"); val != "svc" || !found || err != nil {
+		t.Errorf("Lookup(ADAPTER_NAME) = %q, %v, %v", val, found, err)
+	}
+
+	if _, found, _ := source.Lookup(context.Background(), "ADAPTER_BLANK"); found {
+		t.Error("Lookup(ADAPTER_BLANK) found an empty value")
+	}
+
+	if val, found, _ := envs.EnvSource.Lookup(context.Background(), "ADAPTER_EMPTY"); val != "" || !found {
+		t.Errorf("EnvSource.Lookup(ADAPTER_EMPTY) = %q, %v, want an empty value that is found", val, found)
+	}
+
+	valueFunc := envs.SourceValueFunc(envs.SourceFunc(func(_ context.Context, key string) (string, bool, error) {
+		if key == "BROKEN" {
+			return "", false, errors.New("connection refused")
+		}
+
+		return "value", true, nil
+	}))
+
+	if got := valueFunc("ADAPTER_NAME", "def"); got != "value" {
+		t.Errorf("SourceValueFunc()(ADAPTER_NAME) = %q, want value", got)
+	}
+
+	if got := valueFunc("BROKEN", "def"); got != "def" {
+		t.Errorf("SourceValueFunc()(BROKEN) = %q, want def", got)
+	}
+}
@@ -1,19 +1,35 @@
 package envs
 
 import (
+	"context"
+	"encoding"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/url"
 	"os"
 	r "reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	ParseEnvFunc = "ParseEnv"
+
+	defaultTagName = "env"
+	// prefixTagName sets the key of a nested struct, like `envPrefix:"DB"`
+	prefixTagName = "envPrefix"
+
+	// source names reported in logs
+	envSource     = "env"
+	customSource  = "custom"
+	defaultSource = "default"
+	presetSource  = "preset"
 )
 
 var (
@@ -22,13 +38,24 @@ var (
 		"2006/01/02", "2006/01/02 15:04:05", time.UnixDate, time.RubyDate}
 
 	stringSeparators = []string{",", ";", ";", "-", " "}
+	// mapListSeparator splits list values of maps, like the a|b|c in grp1:a|b|c,grp2:d|e
+	mapListSeparator = "|"
 
 	EnvParserType = r.TypeOf((*EnvParser)(nil)).Elem()
 	timeType      = r.TypeOf(time.Time{})
 	durationType  = r.TypeOf(time.Duration(0))
+	fileModeType  = r.TypeOf(os.FileMode(0))
 	urlType       = r.TypeOf(&url.URL{})
+
+	textUnmarshalerType = r.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
 )
 
+// ErrNotSet is returned when a required value could not be found
+var ErrNotSet = errors.New("value is not set")
+
+// ErrCycle is returned for struct types that contain themselves, directly or through pointers, slices or maps
+var ErrCycle = errors.New("recursive struct type")
+
 var (
 	// DefaultGetFunc can be used to use any string value as parser input
 	// for example need to make a network call or socket reading for any specific key
@@ -42,6 +69,17 @@ var (
 		return val
 	}
 
+	// DefaultLookupFunc reads key with os.LookupEnv, so a variable set to an empty string is found and
+	// blanks out the default of its field
+	DefaultLookupFunc LookupFunc = func(key, def string) (string, bool, error) {
+		val, ok := os.LookupEnv(key)
+		if !ok {
+			return def, false, nil
+		}
+
+		return val, true, nil
+	}
+
 	// DefaultKeyFunc is here to support a common practice in working with environment variables which is giving
 	// env keys some kind of namespace like `APPNAME_SERVER_PORT` it replaces all . notions in struct names into _ char.
 	DefaultKeyFunc KeyFunc = func(key string) string {
@@ -57,6 +95,22 @@ type EnvParser interface {
 // ValueFunc is the function is required because sometimes we need to read values sources other than os.getEnv
 type ValueFunc func(key, def string) string
 
+// ValueFuncCtx reads the value of key like ValueFunc, it should give up once ctx is done and report
+// failed lookups with an error
+type ValueFuncCtx func(ctx context.Context, key, def string) (string, error)
+
+// ValueHook rewrites the raw value of key before it is parsed, like resolving a reference to a secret store,
+// it should return value unchanged when it does not apply
+type ValueHook func(ctx context.Context, key, value string) (string, error)
+
+// LookupFunc reads the value of key and reports whether it is set, so a key set to an empty string is told apart
+// from a missing one, failed lookups are reported with an error
+type LookupFunc func(key, def string) (value string, found bool, err error)
+
+// FieldKeyFunc returns the dotted key of a struct field, path is the dotted path of the field like Server.Port and
+// prefix is the dotted key of its parent. the returned key is passed to the KeyFunc and is the prefix of nested fields
+type FieldKeyFunc func(field r.StructField, path, prefix string) string
+
 // KeyFunc is a function that returns altered keys, for example some times you need
 // to replace some characters or you need to add a prefix or suffix
 type KeyFunc func(string) string
@@ -65,24 +119,197 @@ type GetFunc func(name, def string) string
 type Parser struct {
 	BuildKey KeyFunc
 	Get      func(name, def string) string
+
+	mu          sync.Mutex
+	resolutions []Resolution
+
+	prefix     string
+	tagName    string
+	separators []string
+	// timeFormats are the layouts time values are parsed with, timeFormats of the package when it is empty
+	timeFormats []string
+	strict      bool
+	logger      *slog.Logger
+	sourceName  string
+
+	// concurrency is the number of parallel value reads, see WithConcurrency
+	concurrency int
+	// getCtx replaces Get when it is set, see WithValueFuncCtx
+	getCtx ValueFuncCtx
+	// lookupFunc replaces Get and getCtx when it is set, see WithLookupFunc
+	lookupFunc LookupFunc
+	// source replaces lookupFunc, getCtx and Get when it is set, see WithSource
+	source Source
+	// hooks rewrite values before they are parsed, see WithValueHook
+	hooks []ValueHook
+	// template executes values as templates before the hooks run, see WithTemplate
+	template *templateOptions
+	// expandVars substitutes variable references in values, see WithExpand
+	expandVars bool
+	// jsonValues decodes slice, map and struct values written as JSON, see WithJSONValues
+	jsonValues bool
+	// fieldKeyFunc replaces the tag and field name based keys when it is set, see WithFieldKeyFunc
+	fieldKeyFunc FieldKeyFunc
+	// emptyAsUnset treats keys set to an empty string as missing, see WithEmptyAsUnset
+	emptyAsUnset bool
+	// preserve keeps values set before parsing for keys that are not found, see WithPreserveValues
+	preserve bool
+	// reset zeroes fields without a value or default, see WithResetUnset
+	reset bool
+	// caseInsensitive matches environment variables regardless of case, see WithCaseInsensitive
+	caseInsensitive bool
+	// casing is how keys of untagged fields are derived from their names, see WithKeyCasing
+	casing KeyCasing
+	// requireTag skips fields without a tag, see WithRequireTag
+	requireTag bool
+	// delimiter joins the fields of nested structs to the key of their struct, see WithDelimiter
+	delimiter string
 }
 
 func NewParser(keyFunc KeyFunc, valueFunc ValueFunc) *Parser {
+	var lookupFunc LookupFunc
+
+	sourceName := customSource
 	if valueFunc == nil {
 		valueFunc = DefaultGetFunc
+		lookupFunc = DefaultLookupFunc
+		sourceName = envSource
 	}
 
 	if keyFunc == nil {
 		keyFunc = DefaultKeyFunc
 	}
 
-	return &Parser{BuildKey: keyFunc, Get: valueFunc}
+	return &Parser{
+		BuildKey:   keyFunc,
+		Get:        valueFunc,
+		tagName:    defaultTagName,
+		separators: stringSeparators,
+		sourceName: sourceName,
+		lookupFunc: lookupFunc,
+	}
+}
+
+// Unmarshal parses environment variables into dest using a Parser built from opts,
+// use WithPrefix to read prefixed keys.
+func Unmarshal(dest interface{}, opts ...Option) error {
+	return NewParserOpts(opts...).ParseStruct(dest, "")
 }
 
 // ParseStruct is the main entry for parsing environment variables into a struct.
-//
+// an empty prefix falls back to the one configured with WithPrefix.
+func (m *Parser) ParseStruct(dest interface{}, prefix string) error {
+	return m.ParseStructCtx(context.Background(), dest, prefix)
+}
+
+// ParseStructCtx is ParseStruct that stops once ctx is done, ctx is passed to the function set with WithValueFuncCtx
+func (m *Parser) ParseStructCtx(ctx context.Context, dest interface{}, prefix string) error {
+	return m.decode(&decodeState{ctx: ctx}, dest, prefix)
+}
+
+// ParseStructFrom is ParseStruct reading values from environ, a list of KEY=VALUE pairs like os.Environ returns
+// or exec.Cmd.Env takes, instead of the process environment or value function. later pairs win over earlier ones
+func (m *Parser) ParseStructFrom(environ []string, dest interface{}, prefix string) error {
+	return m.decode(&decodeState{environ: snapshotEnviron(environ, m.caseInsensitive), external: true}, dest, prefix)
+}
+
+// decode runs ParseStruct with st, which can carry precompiled fields
+func (m *Parser) decode(st *decodeState, dest interface{}, prefix string) error {
+	if prefix == "" {
+		prefix = m.prefix
+	}
+
+	st.root = prefix
+	if st.environ == nil && m.caseInsensitive && m.sourceName == envSource {
+		st.environ = snapshotEnviron(os.Environ(), true)
+	}
+
+	if m.concurrency > 1 && st.environ == nil {
+		values, err := m.prefetch(st.context(), dest, prefix)
+		if err != nil {
+			return err
+		}
+
+		st.values = values
+	}
+
+	err := m.parseStruct(st, dest, prefix, "")
+	m.setResolutions(st.resolutions)
+
+	return err
+}
+
+// decodeState carries what a single ParseStruct call collects on its way through nested values
+type decodeState struct {
+	resolutions []Resolution
+	// fields are the precompiled fields of struct types, see Compile
+	fields map[r.Type][]structField
+	// values are read ahead of parsing by built key, see WithConcurrency
+	values map[string]fetched
+	// environ replaces the value function, see ParseStructFrom and WithCaseInsensitive
+	environ *environSnapshot
+	// external is set when environ was given to ParseStructFrom instead of read from the process environment
+	external bool
+	// expanded are the values of the fields parsed so far by built key, secrets are left out, see WithTemplate
+	expanded map[string]string
+	// visiting are the struct types being parsed, a type found in its own fields is a cycle
+	visiting map[r.Type]bool
+	// root is the prefix parsing started with, see WithDelimiter
+	root string
+	ctx  context.Context
+}
+
+// environSnapshot holds KEY=VALUE pairs by exact name and, when matching regardless of case, the names by upper case
+type environSnapshot struct {
+	exact map[string]string
+	fold  map[string]string
+}
+
+// snapshotEnviron indexes KEY=VALUE pairs, later pairs win over earlier ones with the same name.
+// with fold the first of several names differing only in case is used for case insensitive matches
+func snapshotEnviron(environ []string, fold bool) *environSnapshot {
+	snap := &environSnapshot{exact: make(map[string]string, len(environ))}
+	if fold {
+		snap.fold = make(map[string]string, len(environ))
+	}
+
+	for _, kv := range environ {
+		k, v := cutEnv(kv)
+		snap.exact[k] = v
+		if _, ok := snap.fold[strings.ToUpper(k)]; fold && !ok {
+			snap.fold[strings.ToUpper(k)] = k
+		}
+	}
+
+	return snap
+}
+
+// lookup returns the value of key, an exact match is preferred over one differing in case
+func (e *environSnapshot) lookup(key string) (string, bool) {
+	v, ok := e.exact[e.name(key)]
+	return v, ok
+}
+
+// name returns the name of the pair key matches
+func (e *environSnapshot) name(key string) string {
+	if _, ok := e.exact[key]; ok {
+		return key
+	}
+
+	return e.fold[strings.ToUpper(key)]
+}
+
+// context returns the context of the running parse, values parsed with ParseValue have none
+func (st *decodeState) context() context.Context {
+	if st.ctx == nil {
+		return context.Background()
+	}
+
+	return st.ctx
+}
+
 //nolint:funlen
-func (m *Parser) ParseStruct(dest interface{}, prefix string) (err error) {
+func (m *Parser) parseStruct(st *decodeState, dest interface{}, prefix, path string) (err error) {
 	dst := r.ValueOf(dest)
 	valueType := dst.Type()
 
@@ -102,45 +329,431 @@ func (m *Parser) ParseStruct(dest interface{}, prefix string) (err error) {
 	valueType = valueType.Elem()
 	dst = dst.Elem()
 
-	for i := 0; i < valueType.NumField(); i++ {
-		fieldValue := dst.Field(i)
-		fieldType := valueType.Field(i)
+	if st.visiting[valueType] {
+		return fmt.Errorf("%s: %w %s", path, ErrCycle, valueType)
+	}
+
+	if st.visiting == nil {
+		st.visiting = map[r.Type]bool{}
+	}
+
+	st.visiting[valueType] = true
+	defer delete(st.visiting, valueType)
+
+	for _, sf := range m.fieldsOf(st, valueType) {
+		fieldValue := dst.Field(sf.index)
+		fieldPath := joinKey(path, sf.name)
+		key, opts := m.fieldKey(sf, st.root, prefix, fieldPath), sf.opts
+
+		if err = st.context().Err(); err != nil {
+			return err
+		}
+
+		if opts.inline && isNested(sf.typ) {
+			if err = m.parseValue(st, fieldValue, "", prefix, key, fieldPath); err != nil {
+				return err
+			}
 
-		if !fieldType.IsExported() {
 			continue
 		}
 
-		// we did already got rid of unExported values
-		tagVal, hasKey := fieldType.Tag.Lookup("env")
-		if !hasKey {
-			tagVal = strings.ToUpper(convertUpperCaseWithUnderLine(dst.Type().Field(i).Name))
+		// fields set before parsing keep their value over the default, see WithPreserveValues
+		def := opts.def
+		preset := m.preserve && !isNested(sf.typ) && !fieldValue.IsZero()
+		if preset {
+			def = ""
 		}
 
-		// set string up
-		key, def := parseStructTags(tagVal)
-		if prefix != "" {
-			key = fmt.Sprintf("%s.%s", prefix, key)
+		f, err := m.lookup(st, m.fieldKeys(sf, st.root, prefix, key), def)
+		if err != nil {
+			return err
 		}
 
-		// KeyBuilder removes
-		strValues := m.Get(m.BuildKey(key), def)
+		builtKey, strValues := f.key, f.value
+		if f.found && slices.Contains(m.relatedKeys(sf, st.root, prefix, opts.deprecated), f.key) {
+			m.deprecated(f.key, m.BuildKey(key))
+		}
+
+		if preset && !f.found {
+			res := Resolution{FieldPath: fieldPath, Key: builtKey, Source: presetSource, Raw: m.format(fieldValue)}
+			if opts.secret {
+				res.Raw = mask(res.Raw)
+			}
+
+			st.resolutions = append(st.resolutions, res)
+			continue
+		}
+
+		if !isNested(sf.typ) || strValues != "" {
+			res := m.resolution(fieldPath, builtKey, strValues, f.found, f.usedDefault)
+			if opts.secret {
+				res.Raw = mask(res.Raw)
+			}
+
+			st.resolutions = append(st.resolutions, res)
+		}
+
+		if strValues == "" && !isNested(sf.typ) {
+			if m.required(opts) {
+				return fmt.Errorf("%s: %w%s", builtKey, ErrNotSet, opts.hint())
+			}
+
+			// a key set to an empty string blanks the field out, so does a missing one with WithResetUnset
+			if f.found || m.reset {
+				fieldValue.Set(r.Zero(sf.typ))
+			}
+
+			if err = m.scrub(st, f, opts); err != nil {
+				return err
+			}
 
-		if strValues == "" && fieldType.Type.Kind() != r.Struct {
 			continue
 		}
 
-		err = m.ParseValue(fieldValue, strValues, prefix, key)
+		if opts.unescape {
+			strValues = unescape(strValues)
+		}
+
+		if opts.octal {
+			if strValues, err = octalToDecimal(strValues); err != nil && opts.secret {
+				return redactError(err, builtKey)
+			}
+
+			if err != nil {
+				return fmt.Errorf("%s: %w%s", builtKey, err, opts.hint())
+			}
+		}
+
+		if strValues, err = m.expandRefs(st, strValues); err != nil {
+			return fmt.Errorf("%s: %w", builtKey, err)
+		}
+
+		if strValues, err = m.executeTemplate(st, builtKey, strValues); err != nil {
+			return fmt.Errorf("%s: %w", builtKey, err)
+		}
+
+		if strValues, err = m.hook(st.context(), builtKey, strValues); err != nil {
+			return fmt.Errorf("%s: %w", builtKey, err)
+		}
+
+		if m.template != nil && !opts.secret {
+			if st.expanded == nil {
+				st.expanded = map[string]string{}
+			}
+
+			st.expanded[builtKey] = strValues
+		}
+
+		err = m.parseValue(st, fieldValue, strValues, prefix, key, fieldPath)
+		if err != nil && opts.secret {
+			return redactError(err, builtKey)
+		}
+
+		if err != nil && !isNested(sf.typ) {
+			return fmt.Errorf("%s: %w%s", builtKey, err, opts.hint())
+		}
+
 		if err != nil {
 			return err
 		}
+
+		if err = m.scrub(st, f, opts); err != nil {
+			return err
+		}
+	}
+
+	// helper types like TLSConfig build their result once all of their fields are parsed
+	if f, ok := dest.(finisher); ok {
+		if err = f.finish(); err != nil {
+			return fmt.Errorf("%s: %w", m.BuildKey(prefix), err)
+		}
 	}
 
 	return nil
 }
 
+// finisher is implemented by the helper structs of the package that turn their parsed fields into a ready value
+type finisher interface {
+	finish() error
+}
+
+// scrub removes the variable f was read from out of the process environment for fields tagged with `unset`
+func (m *Parser) scrub(st *decodeState, f fetched, opts tagOptions) error {
+	if !opts.unset || !f.found || m.sourceName != envSource || st.external {
+		return nil
+	}
+
+	name := f.key
+	if st.environ != nil {
+		name = st.environ.name(f.key)
+	}
+
+	if err := os.Unsetenv(name); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
+
+// structField is what the parser needs to know about an exported struct field, it only depends on the field
+// and the tag name so it can be computed once per type
+type structField struct {
+	index int
+	name  string
+	typ   r.Type
+	field r.StructField
+	// key is the field key without any prefix
+	key  string
+	opts tagOptions
+}
+
+// fieldCacheKey identifies the fields of a struct type read with a tag name
+type fieldCacheKey struct {
+	t          r.Type
+	tag        string
+	casing     KeyCasing
+	requireTag bool
+}
+
+// fieldCache keeps the []structField of every struct type already parsed, keyed by fieldCacheKey
+var fieldCache sync.Map
+
+// structFields returns the exported fields of the struct type t, they are derived once per type and options
+func (m *Parser) structFields(t r.Type) []structField {
+	cacheKey := fieldCacheKey{t: t, tag: m.tag(), casing: m.casing, requireTag: m.requireTag}
+	if fields, ok := fieldCache.Load(cacheKey); ok {
+		return fields.([]structField)
+	}
+
+	fields, _ := fieldCache.LoadOrStore(cacheKey, m.readFields(t))
+	return fields.([]structField)
+}
+
+// readFields reads the exported fields of the struct type t and their tags
+func (m *Parser) readFields(t r.Type) []structField {
+	fields := make([]structField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// we did already got rid of unExported values
+		if !field.IsExported() {
+			continue
+		}
+
+		tagVal, hasKey := field.Tag.Lookup(m.tag())
+		// `env:"-"` skips the field and everything nested in it, like encoding/json
+		if hasKey && strings.TrimSpace(tagVal) == "-" {
+			continue
+		}
+
+		_, hasPrefix := field.Tag.Lookup(prefixTagName)
+		if !hasKey && !hasPrefix && m.requireTag {
+			continue
+		}
+
+		if !hasKey {
+			tagVal = m.casing.key(field.Name)
+		}
+
+		opts := parseStructTags(tagVal)
+		// private keys never show up in dumps, diffs or resolutions even without the secret option
+		if isKeyType(field.Type) {
+			opts.secret = true
+		}
+
+		if prefix := strings.Trim(field.Tag.Get(prefixTagName), "_."); prefix != "" && isNested(field.Type) {
+			opts.key, opts.inline = prefix, false
+		}
+
+		fields = append(fields, structField{
+			index: i, name: field.Name, typ: field.Type, field: field, key: opts.key, opts: opts,
+		})
+	}
+
+	return fields
+}
+
+// fieldsOf returns the fields of t, precompiled ones from st are used when there are any
+func (m *Parser) fieldsOf(st *decodeState, t r.Type) []structField {
+	if fields, ok := st.fields[t]; ok {
+		return fields
+	}
+
+	return m.structFields(t)
+}
+
+// fieldKey returns the dotted key of sf under prefix, path is the dotted path of the field and root the prefix
+// parsing started with. inline structs take the key of their parent and absolute fields ignore prefix
+func (m *Parser) fieldKey(sf structField, root, prefix, path string) string {
+	if sf.opts.inline && isNested(sf.typ) {
+		return prefix
+	}
+
+	if sf.opts.absolute {
+		return sf.key
+	}
+
+	if m.fieldKeyFunc != nil {
+		return m.fieldKeyFunc(sf.field, path, prefix)
+	}
+
+	return m.joinField(root, prefix, sf.key)
+}
+
+// fieldKeys returns the built keys sf is read from, key first, then its aliases and deprecated keys
+func (m *Parser) fieldKeys(sf structField, root, prefix, key string) []string {
+	keys := make([]string, 0, len(sf.opts.aliases)+len(sf.opts.deprecated)+1)
+	keys = append(keys, m.BuildKey(key))
+	keys = append(keys, m.relatedKeys(sf, root, prefix, sf.opts.aliases)...)
+
+	return append(keys, m.relatedKeys(sf, root, prefix, sf.opts.deprecated)...)
+}
+
+// relatedKeys builds the alias or deprecated keys of sf under prefix
+func (m *Parser) relatedKeys(sf structField, root, prefix string, names []string) []string {
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		if !sf.opts.absolute {
+			name = m.joinField(root, prefix, name)
+		}
+
+		keys = append(keys, m.BuildKey(name))
+	}
+
+	return keys
+}
+
+// joinField joins the key of a field to prefix, fields of nested structs are joined to the key of their struct
+// with the delimiter set by WithDelimiter while the fields under root keep the dot
+func (m *Parser) joinField(root, prefix, key string) string {
+	if m.delimiter == "" || prefix == root || prefix == "" {
+		return joinKey(prefix, key)
+	}
+
+	return prefix + m.delimiter + key
+}
+
+// joinKey joins key to prefix with a dot, the way nested keys and field paths are built
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+// required reports whether a field with opts must have a value
+func (m *Parser) required(opts tagOptions) bool {
+	return opts.required || (m.strict && opts.def == "")
+}
+
+// fetched is a value read from the value function
+type fetched struct {
+	// key is the key the value was found with
+	key   string
+	value string
+	found bool
+	// usedDefault reports whether value is the tag default
+	usedDefault bool
+}
+
+// lookup reads keys in order, or takes them from the values read ahead, until one is found and falls back
+// to def when none is. the first key is reported when none is found
+func (m *Parser) lookup(st *decodeState, keys []string, def string) (fetched, error) {
+	f := fetched{key: keys[0]}
+	for _, key := range keys {
+		v, ok := st.values[key]
+		if st.environ != nil {
+			v.value, v.found = st.environ.lookup(key)
+			v.found, ok = v.found && !(m.emptyAsUnset && v.value == ""), true
+		}
+
+		if !ok {
+			var err error
+			if v.value, v.found, err = m.get(st.context(), key); err != nil {
+				return fetched{}, fmt.Errorf("%s: %w", key, err)
+			}
+		}
+
+		if v.found {
+			f.key, f.value, f.found = key, v.value, true
+			m.logLookup(f)
+			return f, nil
+		}
+	}
+
+	if def != "" {
+		f.value, f.usedDefault = def, true
+	}
+
+	m.logLookup(f)
+	return f, nil
+}
+
+// deprecated reports that the value of newKey was read from the deprecated oldKey
+func (m *Parser) deprecated(oldKey, newKey string) {
+	emit(Event{Kind: EventDeprecated, Key: oldKey, Err: fmt.Errorf("deprecated, use %s instead", newKey)})
+	if m.logger != nil {
+		m.logger.Warn("envs: deprecated key", slog.String("key", oldKey), slog.String("use", newKey))
+	}
+}
+
+// logLookup logs a single lookup at debug level when WithLogger is used
+func (m *Parser) logLookup(f fetched) {
+	if m.logger == nil {
+		return
+	}
+
+	source := m.sourceName
+	if f.usedDefault {
+		source = defaultSource
+	}
+
+	m.logger.Debug("envs: lookup", slog.String("key", f.key), slog.String("source", source),
+		slog.Bool("found", f.found), slog.Bool("default", f.usedDefault), slog.String("value", mask(f.value)))
+}
+
+// get reads key with the lookup function, the context aware value function or Get, whichever is set.
+// values of value functions are found when they are not empty
+func (m *Parser) get(ctx context.Context, key string) (string, bool, error) {
+	if m.source != nil {
+		val, found, err := m.source.Lookup(ctx, key)
+		return val, found && !(m.emptyAsUnset && val == ""), err
+	}
+
+	if m.lookupFunc != nil {
+		val, found, err := m.lookupFunc(key, "")
+		return val, found && !(m.emptyAsUnset && val == ""), err
+	}
+
+	if m.getCtx != nil {
+		val, err := m.getCtx(ctx, key, "")
+		return val, val != "", err
+	}
+
+	val := m.Get(key, "")
+	return val, val != "", nil
+}
+
+// hook runs the value hooks in order, each one receives the value returned by the previous one
+func (m *Parser) hook(ctx context.Context, key, val string) (string, error) {
+	var err error
+	for _, h := range m.hooks {
+		if val, err = h(ctx, key, val); err != nil {
+			return "", err
+		}
+	}
+
+	return val, nil
+}
+
 // ParseValue turns parses string values for specific types defined in reflect.Value
 // key is required to append new key to existing key for nested structs.
 func (m *Parser) ParseValue(reflectValue r.Value, strValue, prefix, key string) error {
+	return m.parseValue(&decodeState{root: prefix}, reflectValue, strValue, prefix, key, "")
+}
+
+// parseValue is ParseValue carrying the state of the running ParseStruct call and the field path of reflectValue
+func (m *Parser) parseValue(st *decodeState, reflectValue r.Value, strValue, prefix, key, path string) error {
 	if !reflectValue.CanSet() {
 		return nil
 	}
@@ -152,7 +765,7 @@ func (m *Parser) ParseValue(reflectValue r.Value, strValue, prefix, key string)
 	// Checking for non-builtin types
 	switch reflectValue.Type() {
 	case timeType:
-		t, err := parseTime(strValue)
+		t, err := parseTimeIn(strValue, m.timeLayouts())
 		if err != nil {
 			return err
 		}
@@ -166,6 +779,14 @@ func (m *Parser) ParseValue(reflectValue r.Value, strValue, prefix, key string)
 
 		reflectValue.Set(r.ValueOf(u))
 		return nil
+	case urlType.Elem():
+		u, err := url.Parse(strValue)
+		if err != nil {
+			return err
+		}
+
+		reflectValue.Set(r.ValueOf(*u))
+		return nil
 	case durationType:
 		d, err := time.ParseDuration(strValue)
 		if err != nil {
@@ -174,6 +795,50 @@ func (m *Parser) ParseValue(reflectValue r.Value, strValue, prefix, key string)
 
 		reflectValue.Set(r.ValueOf(d))
 		return nil
+	case levelType:
+		level, err := ParseLevel(strValue)
+		if err != nil {
+			return err
+		}
+
+		reflectValue.SetInt(int64(level))
+		return nil
+	case levelVarType:
+		level, err := ParseLevel(strValue)
+		if err != nil {
+			return err
+		}
+
+		reflectValue.Addr().Interface().(*slog.LevelVar).Set(level)
+		return nil
+	case signerType, rsaKeyType, ecdsaKeyType, ed25519KeyType:
+		return parseKeyValue(reflectValue, strValue)
+	case certPoolType:
+		pool, err := ParseCertPool(strValue)
+		if err != nil {
+			return err
+		}
+
+		reflectValue.Set(r.ValueOf(pool))
+		return nil
+	case fileModeType:
+		// permissions are written in octal, 644 read as decimal would be a very different mode
+		n, err := parseOctal(strValue)
+		if err != nil {
+			return err
+		}
+
+		reflectValue.SetUint(n)
+		return nil
+	}
+
+	// types that know how to parse themselves
+	if strValue != "" && r.PointerTo(reflectValue.Type()).Implements(textUnmarshalerType) {
+		return reflectValue.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(strValue))
+	}
+
+	if m.isJSONValue(reflectValue.Type(), strValue) {
+		return json.Unmarshal([]byte(strValue), reflectValue.Addr().Interface())
 	}
 
 	// Checking for built int types
@@ -181,13 +846,13 @@ func (m *Parser) ParseValue(reflectValue r.Value, strValue, prefix, key string)
 	case r.String:
 		reflectValue.SetString(strValue)
 	case r.Int, r.Int8, r.Int32, r.Int16, r.Int64:
-		n, err := strconv.ParseInt(strValue, 10, 64)
+		n, err := strconv.ParseInt(strValue, 10, reflectValue.Type().Bits())
 		if err != nil {
 			return err
 		}
 		reflectValue.SetInt(n)
 	case r.Uint, r.Uint8, r.Uint16, r.Uint32, r.Uint64, r.Uintptr:
-		n, err := strconv.ParseUint(strValue, 10, 64)
+		n, err := strconv.ParseUint(strValue, 10, reflectValue.Type().Bits())
 		if err != nil {
 			return err
 		}
@@ -199,6 +864,13 @@ func (m *Parser) ParseValue(reflectValue r.Value, strValue, prefix, key string)
 			return err
 		}
 		reflectValue.SetFloat(f)
+	case r.Complex64, r.Complex128:
+		c, err := strconv.ParseComplex(strings.TrimSpace(strValue), reflectValue.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		reflectValue.SetComplex(c)
 	case r.Bool:
 		b, err := strconv.ParseBool(strValue)
 		if err != nil {
@@ -206,10 +878,14 @@ func (m *Parser) ParseValue(reflectValue r.Value, strValue, prefix, key string)
 		}
 
 		reflectValue.SetBool(b)
+	case r.Pointer:
+		return m.parsePointer(st, reflectValue, strValue, prefix, key, path)
+	case r.Interface:
+		return m.parseInterface(st, reflectValue, strValue, key, path)
 	case r.Map:
-		return m.parseMap(reflectValue, strValue)
+		return m.parseMap(st, reflectValue, strValue)
 	case r.Slice:
-		return m.parseArray(strValue, reflectValue, key)
+		return m.parseArray(st, strValue, reflectValue, key)
 	case r.Struct:
 		// The ParseEnv should be on pointer
 		ptr := reflectValue.Addr()
@@ -234,15 +910,40 @@ func (m *Parser) ParseValue(reflectValue r.Value, strValue, prefix, key string)
 			return nil
 		}
 
-		return m.ParseStruct(reflectValue.Addr().Interface(), key)
+		return m.parseStruct(st, reflectValue.Addr().Interface(), key, path)
 	}
 
 	return nil
 }
 
+// parsePointer parses strValue into a new value and points reflectValue to it, pointers to nested structs
+// are left nil when none of their fields gets a value so optional sections stay unset
+func (m *Parser) parsePointer(st *decodeState, reflectValue r.Value, strValue, prefix, key, path string) error {
+	elemType := reflectValue.Type().Elem()
+	if strValue == "" && !isNested(elemType) {
+		return nil
+	}
+
+	if !reflectValue.IsNil() {
+		return m.parseValue(st, reflectValue.Elem(), strValue, prefix, key, path)
+	}
+
+	elem := r.New(elemType)
+	if err := m.parseValue(st, elem.Elem(), strValue, prefix, key, path); err != nil {
+		return err
+	}
+
+	if elem.Elem().IsZero() && isNested(elemType) {
+		return nil
+	}
+
+	reflectValue.Set(elem)
+	return nil
+}
+
 // parseMap Turns strings like: key1:val1,key2:val2 into map[K]V
-// Only string and int are supported for now.
-func (m *Parser) parseMap(value r.Value, str string) (err error) {
+// keys and values can be of any type parseValue supports, pairs are split on their first colon.
+func (m *Parser) parseMap(st *decodeState, value r.Value, str string) (err error) {
 	if value.Type().Kind() != r.Map {
 		return fmt.Errorf("%s is not a map", value.Type().Name())
 	}
@@ -251,24 +952,35 @@ func (m *Parser) parseMap(value r.Value, str string) (err error) {
 	valueType := value.Type().Elem()
 	value.Set(r.MakeMap(value.Type()))
 
-	kv := splitStr(str)
+	kv := m.splitStr(str)
 	for _, pair := range kv {
-		splt := strings.Split(pair, ":")
-		if len(splt) < 2 {
+		keyStr, valStr, ok := strings.Cut(pair, ":")
+		if !ok {
 			return fmt.Errorf("%s can not is in wrong format as key value pair", pair)
 		}
 
-		keyStr := strings.TrimSpace(splt[0])
-		valStr := strings.TrimSpace(splt[1])
+		keyStr = strings.TrimSpace(keyStr)
+		valStr = strings.TrimSpace(valStr)
 		k := r.New(keyType).Elem()
 		v := r.New(valueType).Elem()
 
-		if err = m.ParseValue(k, keyStr, "", ""); err != nil {
-			return fmt.Errorf("%s can not be parsed as %s", keyStr, k.Kind())
+		// keys go through the same converters as values, so durations and TextUnmarshaler types work as keys
+		if err = m.parseValue(st, k, keyStr, "", "", ""); err != nil {
+			return fmt.Errorf("map key %q can not be parsed as %s: %w", keyStr, keyType, err)
+		}
+
+		if value.MapIndex(k).IsValid() {
+			return fmt.Errorf("duplicate map key %q", keyStr)
 		}
 
-		if err = m.ParseValue(v, valStr, "", ""); err != nil {
-			return fmt.Errorf("%s can not be parsed as %s", valStr, v.Kind())
+		if isMapList(valueType) {
+			err = m.parseMapList(st, v, valStr)
+		} else {
+			err = m.parseValue(st, v, valStr, "", "", "")
+		}
+
+		if err != nil {
+			return fmt.Errorf("map value %q of key %q can not be parsed as %s: %w", valStr, keyStr, valueType, err)
 		}
 
 		value.SetMapIndex(k, v)
@@ -276,8 +988,43 @@ func (m *Parser) parseMap(value r.Value, str string) (err error) {
 	return nil
 }
 
-func (m *Parser) parseArray(value string, fieldValue r.Value, currentKey string) error {
-	splits := splitStr(value)
+// isMapList reports whether map values of type t are lists split on mapListSeparator,
+// slices parsed as a whole like net.IP are not
+func isMapList(t r.Type) bool {
+	if t.Kind() != r.Slice {
+		return false
+	}
+
+	ptr := r.PointerTo(t)
+
+	return !ptr.Implements(textUnmarshalerType) && !ptr.Implements(EnvParserType)
+}
+
+// parseMapList parses a map value like a|b|c into the slice v
+func (m *Parser) parseMapList(st *decodeState, v r.Value, str string) error {
+	if str == "" {
+		v.Set(r.MakeSlice(v.Type(), 0, 0))
+		return nil
+	}
+
+	items := strings.Split(str, mapListSeparator)
+	v.Set(r.MakeSlice(v.Type(), len(items), len(items)))
+	for i, item := range items {
+		if err := m.parseValue(st, v.Index(i), strings.TrimSpace(item), "", "", ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Parser) parseArray(st *decodeState, value string, fieldValue r.Value, currentKey string) error {
+	isURL := isURLType(fieldValue.Type().Elem())
+
+	splits := m.splitStr(value)
+	if isURL {
+		splits = m.splitURLs(value)
+	}
 
 	if len(splits) > fieldValue.Len() {
 		fieldValue.Grow(len(splits) - fieldValue.Len())
@@ -288,7 +1035,15 @@ func (m *Parser) parseArray(value string, fieldValue r.Value, currentKey string)
 	for i, split := range splits {
 		split = strings.TrimSpace(split)
 		// for slice values prefix should become key and there should be no keys
-		err := m.ParseValue(fieldValue.Index(i), split, currentKey, "")
+		err := m.parseValue(st, fieldValue.Index(i), split, currentKey, "", "")
+		if err == nil && isURL {
+			err = checkListURL(fieldValue.Index(i))
+		}
+
+		if err != nil && isURL {
+			return fmt.Errorf("url %d %q: %w", i, split, err)
+		}
+
 		if err != nil {
 			return err
 		}
@@ -297,20 +1052,103 @@ func (m *Parser) parseArray(value string, fieldValue r.Value, currentKey string)
 	return nil
 }
 
-func splitStr(value string) (split []string) {
-	for _, sep := range stringSeparators {
-		split = strings.Split(value, sep)
-		if split[0] != value {
-			return
+// isURLType reports whether t is url.URL or *url.URL
+func isURLType(t r.Type) bool {
+	return t == urlType || t == urlType.Elem()
+}
+
+// checkListURL makes sure an element of a URL list has a scheme, a host written without one
+// is parsed as a path and would only fail once it is dialed
+func checkListURL(v r.Value) error {
+	if v.Kind() == r.Pointer {
+		v = v.Elem()
+	}
+
+	if u := v.Interface().(url.URL); u.Scheme == "" {
+		return errors.New("missing scheme")
+	}
+
+	return nil
+}
+
+// splitStr splits value on the first separator, in order, that it contains
+func (m *Parser) splitStr(value string) []string {
+	sep, ok := m.separatorIn(value, m.separators)
+	if !ok {
+		return []string{value}
+	}
+
+	return strings.Split(value, sep)
+}
+
+// splitURLs is splitStr for URL lists, `-` is never used since host names contain it
+func (m *Parser) splitURLs(value string) []string {
+	all := m.separators
+	if len(all) == 0 {
+		all = stringSeparators
+	}
+
+	separators := make([]string, 0, len(all))
+	for _, sep := range all {
+		if sep != "-" {
+			separators = append(separators, sep)
 		}
 	}
 
-	return
+	sep, ok := m.separatorIn(value, separators)
+	if !ok || len(separators) == 0 {
+		return []string{value}
+	}
+
+	return strings.Split(value, sep)
+}
+
+// separatorIn returns the first of separators found in value, single byte separators are looked up in one scan
+func (m *Parser) separatorIn(value string, separators []string) (string, bool) {
+	if len(separators) == 0 {
+		separators = stringSeparators
+	}
+
+	var present [256]bool
+	for i := 0; i < len(value); i++ {
+		present[value[i]] = true
+	}
+
+	for _, sep := range separators {
+		if len(sep) == 1 && present[sep[0]] || len(sep) != 1 && strings.Contains(value, sep) {
+			return sep, true
+		}
+	}
+
+	return "", false
+}
+
+// timeLayouts returns the layouts the parser reads time values with
+func (m *Parser) timeLayouts() []string {
+	if len(m.timeFormats) == 0 {
+		return timeFormats
+	}
+
+	return m.timeFormats
+}
+
+// tag returns the struct tag name the parser reads keys and defaults from
+func (m *Parser) tag() string {
+	if m.tagName == "" {
+		return defaultTagName
+	}
+
+	return m.tagName
 }
 
 func parseTime(value string) (time.Time, error) {
+	return parseTimeIn(value, timeFormats)
+}
+
+// parseTimeIn parses value with the first of formats that fits it
+func parseTimeIn(value string, formats []string) (time.Time, error) {
 	var err []error
-	for _, format := range timeFormats {
+	for _, format := range formats {
 		t, e := time.Parse(format, value)
 		if e == nil {
 			return t, nil
@@ -320,32 +1158,215 @@ func parseTime(value string) (time.Time, error) {
 	return time.Time{}, errors.Join(err...)
 }
 
-func parseStructTags(tagVal string) (key, def string) {
+// parseOctal parses octal numbers like 0644, 644 or 0o644
+func parseOctal(str string) (uint64, error) {
+	str = strings.TrimSpace(str)
+	if len(str) > 1 && str[0] == '0' && (str[1] == 'o' || str[1] == 'O') {
+		str = str[2:]
+	}
+
+	return strconv.ParseUint(str, 8, 32)
+}
+
+// octalToDecimal rewrites the octal number in str in base 10 for fields tagged with `octal`
+func octalToDecimal(str string) (string, error) {
+	n, err := parseOctal(str)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatUint(n, 10), nil
+}
+
+// tagOptions is the parsed form of `env:"KEY,default=value,secret,required,desc='description',example='value'"`
+type tagOptions struct {
+	key      string
+	def      string
+	desc     string
+	example  string
+	secret   bool
+	required bool
+	// inline nested structs add their fields to the parent without a key of their own
+	inline bool
+	// absolute keys are read without the prefix
+	absolute bool
+	// aliases are tried in order when key is not found, like `env:"DATABASE_URL|DB_URL"`
+	aliases []string
+	// deprecated keys are tried after the aliases and reported with EventDeprecated when used
+	deprecated []string
+	// unset removes the variable from the process environment once its value was read
+	unset bool
+	// octal parses integer values in base 8, like a umask of 022
+	octal bool
+	// unescape turns \n, \r and \t escapes in the value into real characters, for PEM data in a single line
+	unescape bool
+}
+
+// hint describes the field for error messages using its description and example
+func (o tagOptions) hint() string {
+	parts := make([]string, 0, 2)
+	if o.desc != "" {
+		parts = append(parts, o.desc)
+	}
+
+	if o.example != "" {
+		parts = append(parts, "example: "+o.example)
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return " (" + strings.Join(parts, "; ") + ")"
+}
+
+// valueOptions are the `name=value` tag options, their values can contain commas or be single quoted
+var valueOptions = map[string]bool{"default": true, "desc": true, "example": true, "deprecated": true}
+
+func parseStructTags(tagVal string) (opts tagOptions) {
 	tagVal = strings.TrimSpace(tagVal)
 	if tagVal == "-" || tagVal == "" {
-		return "", ""
+		return opts
 	}
 
-	parts := strings.Split(tagVal, ",")
-	key = parts[0]
-	if len(parts) < 2 {
-		return key, ""
+	parts := splitTag(tagVal)
+	keys := splitKeys(parts[0])
+	opts.key, opts.aliases = keys[0], keys[1:]
+
+	// parts that are not a known flag or option belong to the option before them,
+	// which is the default value unless another option was named.
+	values := map[string][]string{}
+	current := "default"
+	for _, part := range parts[1:] {
+		switch strings.TrimSpace(part) {
+		case "secret":
+			opts.secret = true
+			continue
+		case "required":
+			opts.required = true
+			continue
+		case "inline", "squash":
+			opts.inline = true
+			continue
+		case "absolute":
+			opts.absolute = true
+			continue
+		case "unset":
+			opts.unset = true
+			continue
+		case "unescape":
+			opts.unescape = true
+			continue
+		case "octal":
+			opts.octal = true
+			continue
+		}
+
+		if name, val, ok := strings.Cut(strings.TrimSpace(part), "="); ok && valueOptions[name] {
+			current, part = name, val
+		}
+
+		values[current] = append(values[current], part)
 	}
 
-	parts[1] = strings.ReplaceAll(parts[1], "default=", "")
+	opts.def = strings.Join(values["default"], ",")
+	opts.desc = unquoteTag(strings.Join(values["desc"], ","))
+	opts.example = unquoteTag(strings.Join(values["example"], ","))
+	for _, key := range values["deprecated"] {
+		opts.deprecated = append(opts.deprecated, splitKeys(key)...)
+	}
 
-	def = parts[1]
-	if len(parts) > 2 {
-		def = strings.Join(parts[1:], ",")
+	return opts
+}
+
+// splitKeys splits `|` separated keys
+func splitKeys(keys string) []string {
+	split := strings.Split(keys, "|")
+	for i := range split {
+		split[i] = strings.TrimSpace(split[i])
+	}
+
+	return split
+}
+
+// splitTag splits a tag value on commas that are not inside single quotes, a quote only opens right after
+// `=` or a comma and only closes right before a comma or the end, so apostrophes in defaults are left alone.
+func splitTag(tagVal string) []string {
+	parts := make([]string, 0, strings.Count(tagVal, ",")+1)
+	quoted, start := false, 0
+	for i := 0; i < len(tagVal); i++ {
+		switch tagVal[i] {
+		case '\'':
+			if !quoted && (i == start || tagVal[i-1] == '=') {
+				quoted = true
+			} else if quoted && (i == len(tagVal)-1 || tagVal[i+1] == ',') {
+				quoted = false
+			}
+		case ',':
+			if !quoted {
+				parts = append(parts, tagVal[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, tagVal[start:])
+}
+
+// unquoteTag trims val and removes the single quotes around it
+func unquoteTag(val string) string {
+	val = strings.TrimSpace(val)
+	if len(val) >= 2 && val[0] == '\'' && val[len(val)-1] == '\'' {
+		return val[1 : len(val)-1]
 	}
 
-	return key, def
+	return val
 }
 
+// KeyCasing selects how the keys of untagged fields are derived from their names
+type KeyCasing int
+
+const (
+	// ScreamingSnakeCase turns ReadTimeout into READ_TIMEOUT, it is the default
+	ScreamingSnakeCase KeyCasing = iota
+	// SnakeCase turns ReadTimeout into read_timeout
+	SnakeCase
+	// KebabCase turns ReadTimeout into read-timeout
+	KebabCase
+	// CamelCase turns ReadTimeout into readTimeout
+	CamelCase
+)
+
+// key derives the key of a field called name
+func (c KeyCasing) key(name string) string {
+	words := strings.Split(convertUpperCaseWithUnderLine(name), "_")
+
+	switch c {
+	case SnakeCase:
+		return strings.ToLower(strings.Join(words, "_"))
+	case KebabCase:
+		return strings.ToLower(strings.Join(words, "-"))
+	case CamelCase:
+		for i, word := range words {
+			word = strings.ToLower(word)
+			if i > 0 && word != "" {
+				word = strings.ToUpper(word[:1]) + word[1:]
+			}
+
+			words[i] = word
+		}
+
+		return strings.Join(words, "")
+	default:
+		return strings.ToUpper(strings.Join(words, "_"))
+	}
+}
+
+// upperCaseBoundary matches any lower case char next to an uppercase char
+// matches two instance at once (1)(2) we can use later on in
+// re.ReplaceAllString as ${1} , ${2} how ever we want
+var upperCaseBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
 func convertUpperCaseWithUnderLine(in string) string {
-	// this regex matches any lower case char next to an uppercase char
-	// matches two instance at once (1)(2) we can use later on in
-	// re.ReplaceAllString as ${1} , ${2} how ever we want
-	re := regexp.MustCompile(`([a-z0-9])([A-Z])`)
-	return re.ReplaceAllString(in, "${1}_${2}")
+	return upperCaseBoundary.ReplaceAllString(in, "${1}_${2}")
 }
@@ -1,7 +1,10 @@
 package envs_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"reflect"
 	"strconv"
@@ -10,6 +13,7 @@ import (
 	"time"
 
 	"github.com/OZahed/envs"
+	"github.com/OZahed/envs/envtest"
 )
 
 type TestParsVal struct {
@@ -109,9 +113,7 @@ func TestMarshaler_LoadStruct_osSetEnv(t *testing.T) {
 		"APP_SERVER_TLS":     "t",
 	}
 
-	for k, v := range testEnvs {
-		_ = os.Setenv(k, v)
-	}
+	envtest.Set(t, testEnvs)
 
 	date, _ := time.Parse(time.DateTime, timeStr)
 
@@ -190,9 +192,7 @@ func TestMarshaler_ParseStruct_WithoutTags(t *testing.T) {
 		"APP_SERVER_TLS":     "t",
 	}
 
-	for k, v := range testEnvs {
-		_ = os.Setenv(k, v)
-	}
+	envtest.Set(t, testEnvs)
 
 	date, _ := time.Parse(time.DateTime, timeStr)
 
@@ -229,3 +229,566 @@ func TestMarshaler_ParseStruct_WithoutTags(t *testing.T) {
 		}
 	})
 }
+
+func TestUnmarshal(t *testing.T) {
+	type Config struct {
+		Name    string        `env:"NAME,default=unmarshal"`
+		Timeout time.Duration `env:"TIMEOUT,default=3s"`
+		Server  struct {
+			Port int `env:"PORT"`
+		} `env:"SERVER"`
+	}
+
+	t.Setenv("UNMARSHAL_SERVER_PORT", "9090")
+
+	want := Config{Name: "unmarshal", Timeout: 3 * time.Second}
+	want.Server.Port = 9090
+
+	cfg := Config{}
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("UNMARSHAL")); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("got: %v  want: %v", cfg, want)
+	}
+
+	if err := envs.Unmarshal(cfg); err == nil {
+		t.Errorf("Unmarshal() expected error for non pointer destination")
+	}
+}
+
+func TestParseStruct_URL(t *testing.T) {
+	cfg := struct {
+		Pointer *url.URL `env:"URL,default=https://example.com/path"`
+		Value   url.URL  `env:"URL,default=https://example.com/path"`
+	}{}
+
+	if err := envs.NewParser(nil, nil).ParseStruct(&cfg, "STRUCT_URL"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if cfg.Pointer == nil || cfg.Pointer.String() != cfg.Value.String() {
+		t.Errorf("got: %v and %v, want equal urls", cfg.Pointer, cfg.Value.String())
+	}
+}
+
+func TestParseStruct_required(t *testing.T) {
+	cfg := struct {
+		Token string `env:"TOKEN,required"`
+	}{}
+
+	if err := envs.NewParser(nil, nil).ParseStruct(&cfg, "REQUIRED"); !errors.Is(err, envs.ErrNotSet) {
+		t.Errorf("ParseStruct() error = %v, want %v", err, envs.ErrNotSet)
+	}
+
+	t.Setenv("REQUIRED_TOKEN", "token")
+	if err := envs.NewParser(nil, nil).ParseStruct(&cfg, "REQUIRED"); err != nil || cfg.Token != "token" {
+		t.Errorf("ParseStruct() = %v, %v want %v", cfg.Token, err, "token")
+	}
+}
+
+func TestParseStruct_descAndExample(t *testing.T) {
+	type Config struct {
+		Port int    `env:"PORT,desc='port to listen on, defaults to 80',example='8080',required"`
+		Name string `env:"NAME,default=it's mine,secret"`
+	}
+
+	cfg := Config{}
+	err := envs.NewParser(nil, nil).ParseStruct(&cfg, "DESC_EXAMPLE")
+	if !errors.Is(err, envs.ErrNotSet) {
+		t.Fatalf("ParseStruct() error = %v, want %v", err, envs.ErrNotSet)
+	}
+
+	want := "DESC_EXAMPLE_PORT: value is not set (port to listen on, defaults to 80; example: 8080)"
+	if err.Error() != want {
+		t.Errorf("ParseStruct() error = %v, want %v", err, want)
+	}
+
+	t.Setenv("DESC_EXAMPLE_PORT", "80")
+	if err = envs.NewParser(nil, nil).ParseStruct(&cfg, "DESC_EXAMPLE"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if cfg.Port != 80 || cfg.Name != "it's mine" {
+		t.Errorf("got: %+v", cfg)
+	}
+}
+
+func TestParseStruct_tagNames(t *testing.T) {
+	type Config struct {
+		Port    int `env:"ENV_PORT" cfg:"CFG_PORT"`
+		MaxConn int
+	}
+
+	values := map[string]string{"ENV_PORT": "1", "CFG_PORT": "2", "MAX_CONN": "3"}
+	valueFunc := envs.WithValueFunc(func(key, def string) string {
+		return values[key]
+	})
+
+	// the same type is parsed twice so the second parse of each tag name reads cached fields
+	for i := 0; i < 2; i++ {
+		var envCfg, cfgCfg Config
+		if err := envs.Unmarshal(&envCfg, valueFunc); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if err := envs.Unmarshal(&cfgCfg, valueFunc, envs.WithTagName("cfg")); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		if want := (Config{Port: 1, MaxConn: 3}); envCfg != want {
+			t.Errorf("env tags: got %+v, want %+v", envCfg, want)
+		}
+
+		if want := (Config{Port: 2, MaxConn: 3}); cfgCfg != want {
+			t.Errorf("cfg tags: got %+v, want %+v", cfgCfg, want)
+		}
+	}
+}
+
+func BenchmarkParseStruct_list(b *testing.B) {
+	value := strings.Repeat("item;", 200) + "last"
+	p := envs.NewParser(nil, func(key, def string) string { return value })
+
+	for i := 0; i < b.N; i++ {
+		var cfg struct {
+			List []string `env:"LIST"`
+		}
+
+		if err := p.ParseStruct(&cfg, ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParseStructCtx(t *testing.T) {
+	type Config struct {
+		Name string `env:"NAME"`
+		Port int    `env:"PORT,default=8080"`
+	}
+
+	errLookup := errors.New("lookup failed")
+	valueFunc := func(ctx context.Context, key, def string) (string, error) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		switch key {
+		case "CTX_NAME":
+			return "svc", nil
+		case "FAIL_PORT":
+			return "", errLookup
+		}
+
+		return def, nil
+	}
+
+	for _, concurrency := range []int{0, 4} {
+		p := envs.NewParserOpts(envs.WithValueFuncCtx(valueFunc), envs.WithConcurrency(concurrency))
+
+		t.Run(fmt.Sprintf("values/%d", concurrency), func(t *testing.T) {
+			cfg := Config{}
+			if err := p.ParseStructCtx(context.Background(), &cfg, "CTX"); err != nil {
+				t.Fatalf("ParseStructCtx() error = %v", err)
+			}
+
+			if want := (Config{Name: "svc", Port: 8080}); cfg != want {
+				t.Errorf("got: %+v want: %+v", cfg, want)
+			}
+		})
+
+		t.Run(fmt.Sprintf("lookup error/%d", concurrency), func(t *testing.T) {
+			err := p.ParseStructCtx(context.Background(), &Config{}, "FAIL")
+			if !errors.Is(err, errLookup) || !strings.Contains(err.Error(), "FAIL_PORT") {
+				t.Errorf("ParseStructCtx() error = %v, want %v for FAIL_PORT", err, errLookup)
+			}
+		})
+
+		t.Run(fmt.Sprintf("canceled/%d", concurrency), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			if err := p.ParseStructCtx(ctx, &Config{}, "CTX"); !errors.Is(err, context.Canceled) {
+				t.Errorf("ParseStructCtx() error = %v, want %v", err, context.Canceled)
+			}
+		})
+	}
+}
+
+func TestParseStruct_emptyValues(t *testing.T) {
+	type Config struct {
+		Name  string `env:"NAME,default=svc"`
+		Port  int    `env:"PORT,default=8080"`
+		Level string `env:"LEVEL,default=info"`
+	}
+
+	t.Setenv("EMPTY_NAME", "")
+	t.Setenv("EMPTY_PORT", "")
+
+	cfg := Config{Port: 1}
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("EMPTY")); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if want := (Config{Level: "info"}); cfg != want {
+		t.Errorf("got: %+v want: %+v", cfg, want)
+	}
+
+	cfg = Config{}
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("EMPTY"), envs.WithEmptyAsUnset()); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if want := (Config{Name: "svc", Port: 8080, Level: "info"}); cfg != want {
+		t.Errorf("WithEmptyAsUnset got: %+v want: %+v", cfg, want)
+	}
+
+	required := struct {
+		Name string `env:"NAME,required"`
+	}{}
+
+	if err := envs.Unmarshal(&required, envs.WithPrefix("EMPTY")); !errors.Is(err, envs.ErrNotSet) {
+		t.Errorf("Unmarshal() error = %v, want %v", err, envs.ErrNotSet)
+	}
+}
+
+func TestParseStruct_pointers(t *testing.T) {
+	type TLS struct {
+		Cert string `env:"CERT"`
+	}
+
+	type Config struct {
+		Debug    *bool          `env:"DEBUG"`
+		Verbose  *bool          `env:"VERBOSE"`
+		Port     *int           `env:"PORT"`
+		Timeout  *time.Duration `env:"TIMEOUT,default=1s"`
+		Name     **string       `env:"NAME"`
+		Ports    []*int         `env:"PORTS"`
+		TLS      *TLS           `env:"TLS"`
+		Optional *TLS           `env:"OPTIONAL"`
+	}
+
+	t.Setenv("PTR_DEBUG", "false")
+	t.Setenv("PTR_NAME", "svc")
+	t.Setenv("PTR_PORTS", "80,443")
+	t.Setenv("PTR_TLS_CERT", "cert.pem")
+
+	cfg := Config{}
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("PTR")); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if cfg.Debug == nil || *cfg.Debug {
+		t.Errorf("Debug = %v, want a pointer to false", cfg.Debug)
+	}
+
+	if cfg.Verbose != nil || cfg.Port != nil || cfg.Optional != nil {
+		t.Errorf("unset pointers should stay nil, got Verbose: %v Port: %v Optional: %v", cfg.Verbose, cfg.Port,
+			cfg.Optional)
+	}
+
+	if cfg.Timeout == nil || *cfg.Timeout != time.Second {
+		t.Errorf("Timeout = %v, want a pointer to 1s", cfg.Timeout)
+	}
+
+	if cfg.Name == nil || *cfg.Name == nil || **cfg.Name != "svc" {
+		t.Errorf("Name = %v, want svc", cfg.Name)
+	}
+
+	if len(cfg.Ports) != 2 || *cfg.Ports[0] != 80 || *cfg.Ports[1] != 443 {
+		t.Errorf("Ports = %v, want [80 443]", cfg.Ports)
+	}
+
+	if cfg.TLS == nil || cfg.TLS.Cert != "cert.pem" {
+		t.Errorf("TLS = %+v, want cert.pem", cfg.TLS)
+	}
+
+	t.Setenv("PTR_PORT", "http")
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("PTR")); err == nil || !strings.Contains(err.Error(), "PTR_PORT") {
+		t.Errorf("Unmarshal() error = %v, want an error for PTR_PORT", err)
+	}
+}
+
+func TestParseStruct_skipTag(t *testing.T) {
+	type Config struct {
+		Name    string `env:"NAME"`
+		Ignored string `env:"-"`
+		Nested  struct {
+			Value string `env:"VALUE,required"`
+		} `env:"-"`
+	}
+
+	keys := map[string]bool{}
+	p := envs.NewParserOpts(envs.WithValueFunc(func(key, def string) string {
+		keys[key] = true
+		return "value"
+	}))
+
+	cfg := Config{}
+	if err := p.ParseStruct(&cfg, "SKIP"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if cfg.Name != "value" || cfg.Ignored != "" || cfg.Nested.Value != "" {
+		t.Errorf("got: %+v", cfg)
+	}
+
+	if want := map[string]bool{"SKIP_NAME": true}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("looked up %v, want %v", keys, want)
+	}
+
+	var out strings.Builder
+	if err := p.Dump(cfg, &out); err != nil || strings.Count(out.String(), "\n") != 1 {
+		t.Errorf("Dump() = %q, %v, want only SKIP_NAME", out.String(), err)
+	}
+}
+
+type HTTPConfig struct {
+	Host string `env:"HOST,default=localhost"`
+	Port int    `env:"PORT"`
+}
+
+type LogConfig struct {
+	Level string `env:"LOG_LEVEL"`
+}
+
+func TestParseStruct_inline(t *testing.T) {
+	type Config struct {
+		HTTPConfig `env:",inline"`
+		*LogConfig `env:",squash"`
+		Name       string `env:"NAME"`
+	}
+
+	t.Setenv("INLINE_PORT", "8080")
+	t.Setenv("INLINE_LOG_LEVEL", "debug")
+	t.Setenv("INLINE_NAME", "svc")
+
+	for _, concurrency := range []int{0, 2} {
+		cfg := Config{}
+		if err := envs.Unmarshal(&cfg, envs.WithPrefix("INLINE"), envs.WithConcurrency(concurrency)); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		want := Config{HTTPConfig: HTTPConfig{Host: "localhost", Port: 8080}, LogConfig: &LogConfig{Level: "debug"},
+			Name: "svc"}
+		if !reflect.DeepEqual(cfg, want) {
+			t.Errorf("got: %+v want: %+v", cfg, want)
+		}
+
+		var out strings.Builder
+		if err := envs.NewParserOpts(envs.WithPrefix("INLINE")).Dump(cfg, &out); err != nil {
+			t.Fatalf("Dump() error = %v", err)
+		}
+
+		for _, key := range []string{"INLINE_HOST ", "INLINE_PORT ", "INLINE_LOG_LEVEL ", "INLINE_NAME "} {
+			if !strings.Contains(out.String(), key) {
+				t.Errorf("Dump() = %q, missing %s", out.String(), key)
+			}
+		}
+	}
+}
+
+func TestParseStruct_envPrefix(t *testing.T) {
+	type DB struct {
+		Host string `env:"HOST"`
+	}
+
+	type Config struct {
+		Primary    DB  `envPrefix:"DB"`
+		Replica    *DB `envPrefix:"REPLICA_"`
+		HTTPConfig `envPrefix:"HTTP"`
+	}
+
+	t.Setenv("PREFIX_DB_HOST", "primary")
+	t.Setenv("PREFIX_REPLICA_HOST", "replica")
+	t.Setenv("PREFIX_HTTP_PORT", "8080")
+
+	cfg := Config{}
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("PREFIX")); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	want := Config{Primary: DB{Host: "primary"}, Replica: &DB{Host: "replica"},
+		HTTPConfig: HTTPConfig{Host: "localhost", Port: 8080}}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("got: %+v want: %+v", cfg, want)
+	}
+}
+
+func TestParseStruct_absolute(t *testing.T) {
+	type Config struct {
+		Home   string `env:"ABSOLUTE_HOME,absolute,default=/root"`
+		Name   string `env:"NAME"`
+		Server struct {
+			Host string `env:"ABSOLUTE_HOSTNAME,absolute"`
+		} `env:"SERVER"`
+	}
+
+	t.Setenv("ABSOLUTE_HOME", "/home/app")
+	t.Setenv("ABSOLUTE_HOSTNAME", "node-1")
+	t.Setenv("APP_NAME", "svc")
+
+	cfg := Config{}
+	if err := envs.Unmarshal(&cfg, envs.WithPrefix("APP")); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if cfg.Home != "/home/app" || cfg.Name != "svc" || cfg.Server.Host != "node-1" {
+		t.Errorf("got: %+v", cfg)
+	}
+}
+
+func TestParseStruct_aliases(t *testing.T) {
+	type Config struct {
+		DatabaseURL string `env:"DATABASE_URL|DB_URL|POSTGRES_URL"`
+		Region      string `env:"REGION|ZONE,default=eu"`
+		Host        string `env:"ALIAS_HOST|ALIAS_HOSTNAME,absolute"`
+	}
+
+	t.Setenv("APP_DB_URL", "postgres://db")
+	t.Setenv("APP_POSTGRES_URL", "postgres://other")
+	t.Setenv("ALIAS_HOSTNAME", "node-1")
+
+	for _, concurrency := range []int{0, 2} {
+		cfg := Config{}
+		p := envs.NewParserOpts(envs.WithPrefix("APP"), envs.WithConcurrency(concurrency))
+		if err := p.ParseStruct(&cfg, ""); err != nil {
+			t.Fatalf("ParseStruct() error = %v", err)
+		}
+
+		if want := (Config{DatabaseURL: "postgres://db", Region: "eu", Host: "node-1"}); cfg != want {
+			t.Errorf("got: %+v want: %+v", cfg, want)
+		}
+
+		res := p.Resolutions()
+		if res[0].Key != "APP_DB_URL" || res[1].Key != "APP_REGION" || res[2].Key != "ALIAS_HOSTNAME" {
+			t.Errorf("Resolutions() = %+v", res)
+		}
+	}
+}
+
+type recursiveNode struct {
+	Name string         `env:"NAME"`
+	Next *recursiveNode `env:"NEXT"`
+}
+
+func TestParseStruct_cycle(t *testing.T) {
+	for _, concurrency := range []int{0, 2} {
+		err := envs.Unmarshal(&recursiveNode{}, envs.WithPrefix("CYCLE"), envs.WithConcurrency(concurrency))
+		if !errors.Is(err, envs.ErrCycle) || !strings.Contains(err.Error(), "Next") {
+			t.Errorf("Unmarshal() error = %v, want %v for Next", err, envs.ErrCycle)
+		}
+	}
+
+	if err := envs.Dump(recursiveNode{}, &strings.Builder{}); !errors.Is(err, envs.ErrCycle) {
+		t.Errorf("Dump() error = %v, want %v", err, envs.ErrCycle)
+	}
+
+	type Config struct {
+		Primary struct {
+			Host string `env:"HOST"`
+		} `env:"PRIMARY"`
+		Replica struct {
+			Host string `env:"HOST"`
+		} `env:"REPLICA"`
+	}
+
+	if err := envs.Unmarshal(&Config{}); err != nil {
+		t.Errorf("Unmarshal() error = %v, sibling structs of the same type are not a cycle", err)
+	}
+}
+
+func TestParseStructFrom(t *testing.T) {
+	type Config struct {
+		Name string `env:"NAME,default=svc"`
+		Port int    `env:"PORT"`
+		Host string `env:"HOST"`
+	}
+
+	t.Setenv("FROM_HOST", "process")
+
+	environ := []string{"FROM_PORT=80", "FROM_PORT=8080", "from_name=lower", "OTHER=1"}
+
+	cfg := Config{}
+	if err := envs.NewParser(nil, nil).ParseStructFrom(environ, &cfg, "FROM"); err != nil {
+		t.Fatalf("ParseStructFrom() error = %v", err)
+	}
+
+	if want := (Config{Name: "svc", Port: 8080}); cfg != want {
+		t.Errorf("got: %+v want: %+v", cfg, want)
+	}
+
+	cfg = Config{}
+	p := envs.NewParserOpts(envs.WithCaseInsensitive())
+	if err := p.ParseStructFrom(environ, &cfg, "FROM"); err != nil {
+		t.Fatalf("ParseStructFrom() error = %v", err)
+	}
+
+	if want := (Config{Name: "lower", Port: 8080}); cfg != want {
+		t.Errorf("WithCaseInsensitive got: %+v want: %+v", cfg, want)
+	}
+}
+
+func TestParseStruct_unescape(t *testing.T) {
+	type Config struct {
+		Cert string `env:"CERT,unescape"`
+		Raw  string `env:"RAW"`
+	}
+
+	values := envs.FromMap(map[string]string{
+		"TLS_CERT": `-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n`,
+		"TLS_RAW":  `a\nb`,
+	})
+
+	var got Config
+	if err := envs.NewParserOpts(envs.WithValueFunc(values)).ParseStruct(&got, "TLS"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	want := Config{Cert: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n", Raw: `a\nb`}
+	if got != want {
+		t.Errorf("ParseStruct() = %q, want %q", got, want)
+	}
+}
+
+func TestParseStruct_unset(t *testing.T) {
+	type Config struct {
+		Password string `env:"PASSWORD,secret,unset"`
+		User     string `env:"USER"`
+		Token    string `env:"TOKEN,unset"`
+	}
+
+	t.Setenv("SCRUB_PASSWORD", "s3cret")
+	t.Setenv("SCRUB_USER", "admin")
+	t.Setenv("scrub_token", "t0ken")
+
+	var got Config
+	if err := envs.NewParserOpts(envs.WithCaseInsensitive()).ParseStruct(&got, "SCRUB"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if want := (Config{Password: "s3cret", User: "admin", Token: "t0ken"}); got != want {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+
+	for _, key := range []string{"SCRUB_PASSWORD", "scrub_token"} {
+		if _, ok := os.LookupEnv(key); ok {
+			t.Errorf("%s is still set after parsing", key)
+		}
+	}
+
+	if _, ok := os.LookupEnv("SCRUB_USER"); !ok {
+		t.Error("SCRUB_USER was unset without the unset option")
+	}
+
+	t.Setenv("SCRUB_PASSWORD", "s3cret")
+	environ := []string{"SCRUB_PASSWORD=other"}
+	if err := envs.NewParser(nil, nil).ParseStructFrom(environ, &got, "SCRUB"); err != nil {
+		t.Fatalf("ParseStructFrom() error = %v", err)
+	}
+
+	if _, ok := os.LookupEnv("SCRUB_PASSWORD"); !ok {
+		t.Error("ParseStructFrom() unset a variable of the process environment")
+	}
+}
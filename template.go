@@ -0,0 +1,53 @@
+package envs
+
+import (
+	"os"
+	"strings"
+	"text/template"
+)
+
+// templateOptions are the functions and data values are executed with, see WithTemplate
+type templateOptions struct {
+	funcs template.FuncMap
+	data  map[string]interface{}
+}
+
+// WithTemplate executes values containing {{ as text/template templates before the value hooks run, enabling
+// computed values like `{{ .Hostname }}-worker`. templates can use .Hostname, .Values holding the values of the
+// fields parsed before, by key and without secrets, the env function reading the process environment, the
+// functions in funcs and the entries of data on top level
+func WithTemplate(funcs template.FuncMap, data map[string]interface{}) Option {
+	return func(p *Parser) {
+		p.template = &templateOptions{funcs: funcs, data: data}
+	}
+}
+
+// executeTemplate executes val as a template when WithTemplate is used
+func (m *Parser) executeTemplate(st *decodeState, key, val string) (string, error) {
+	if m.template == nil || !strings.Contains(val, "{{") {
+		return val, nil
+	}
+
+	funcs := template.FuncMap{"env": os.Getenv}
+	for name, fn := range m.template.funcs {
+		funcs[name] = fn
+	}
+
+	tmpl, err := template.New(key).Funcs(funcs).Option("missingkey=error").Parse(val)
+	if err != nil {
+		return "", err
+	}
+
+	hostname, _ := os.Hostname()
+	data := map[string]interface{}{"Hostname": hostname, "Values": st.expanded}
+	for k, v := range m.template.data {
+		data[k] = v
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
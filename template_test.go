@@ -0,0 +1,55 @@
+package envs_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/OZahed/envs"
+)
+
+func TestWithTemplate(t *testing.T) {
+	type Config struct {
+		Region   string `env:"REGION"`
+		Password string `env:"PASSWORD,secret"`
+		Worker   string `env:"WORKER,default={{ .Hostname }}-worker"`
+		Queue    string `env:"QUEUE"`
+		Literal  string `env:"LITERAL"`
+	}
+
+	t.Setenv("TMPL_NAMESPACE", "prod")
+
+	values := envs.FromMap(map[string]string{
+		"TMPL_REGION":   "eu",
+		"TMPL_PASSWORD": "s3cret",
+		"TMPL_QUEUE":    `{{ upper .Values.TMPL_REGION }}-{{ env "TMPL_NAMESPACE" }}-{{ .Team }}`,
+		"TMPL_LITERAL":  "no template",
+	})
+
+	funcs := template.FuncMap{"upper": strings.ToUpper}
+	p := envs.NewParserOpts(envs.WithValueFunc(values), envs.WithTemplate(funcs, map[string]interface{}{"Team": "core"}))
+
+	var got Config
+	if err := p.ParseStruct(&got, "TMPL"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	hostname, _ := os.Hostname()
+	want := Config{Region: "eu", Password: "s3cret", Worker: hostname + "-worker", Queue: "EU-prod-core",
+		Literal: "no template"}
+	if got != want {
+		t.Errorf("ParseStruct() = %+v, want %+v", got, want)
+	}
+
+	values = envs.FromMap(map[string]string{"TMPL_QUEUE": "{{ .Values.TMPL_PASSWORD }}"})
+	p = envs.NewParserOpts(envs.WithValueFunc(values), envs.WithTemplate(nil, nil))
+	if err := p.ParseStruct(&got, "TMPL"); err == nil || !strings.Contains(err.Error(), "TMPL_QUEUE") {
+		t.Errorf("ParseStruct() error = %v, want an error for a secret or missing value", err)
+	}
+
+	p = envs.NewParserOpts(envs.WithValueFunc(values))
+	if err := p.ParseStruct(&got, "TMPL"); err != nil || got.Queue != "{{ .Values.TMPL_PASSWORD }}" {
+		t.Errorf("ParseStruct() = %+v, %v, want templates left alone without WithTemplate", got, err)
+	}
+}
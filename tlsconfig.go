@@ -0,0 +1,107 @@
+package envs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	r "reflect"
+)
+
+var tlsConfigType = r.TypeOf(&tls.Config{})
+
+// TLSConfig reads certificates, keys and CA bundles as files or inline PEM and turns them into Config while
+// parsing, so services only embed it in their configuration like
+//
+//	type Config struct {
+//		TLS envs.TLSConfig `env:"TLS"` // reads TLS_CERT_FILE, TLS_KEY_FILE, TLS_CA_FILE, ...
+//	}
+//
+// inline values win over files. Config stays nil when none of the fields is set
+type TLSConfig struct {
+	CertFile string `env:"CERT_FILE,desc=path to the PEM encoded certificate chain"`
+	KeyFile  string `env:"KEY_FILE,desc=path to the PEM encoded private key"`
+	CAFile   string `env:"CA_FILE,desc=path to the PEM encoded CA bundle"`
+	// Cert, Key and CA hold inline PEM data, \n escapes are turned into new lines
+	Cert string `env:"CERT,unescape"`
+	Key  string `env:"KEY,secret,unescape"`
+	CA   string `env:"CA,unescape"`
+	// ServerName is used to verify the certificate of servers, it defaults to the host dialed
+	ServerName         string `env:"SERVER_NAME"`
+	InsecureSkipVerify bool   `env:"INSECURE_SKIP_VERIFY"`
+
+	// Config is built from the fields above, the CA bundle is used as both RootCAs and ClientCAs,
+	// servers verifying clients still have to set ClientAuth
+	Config *tls.Config `env:"-"`
+}
+
+// finish builds Config from the parsed fields
+func (c *TLSConfig) finish() error {
+	c.Config = nil
+
+	cert, err := pemData(c.Cert, c.CertFile)
+	if err != nil {
+		return fmt.Errorf("certificate: %w", err)
+	}
+
+	key, err := pemData(c.Key, c.KeyFile)
+	if err != nil {
+		return fmt.Errorf("key: %w", err)
+	}
+
+	ca, err := pemData(c.CA, c.CAFile)
+	if err != nil {
+		return fmt.Errorf("CA: %w", err)
+	}
+
+	if len(cert) == 0 && len(key) == 0 && len(ca) == 0 && c.ServerName == "" && !c.InsecureSkipVerify {
+		return nil
+	}
+
+	// #nosec G402 -- skipping verification is an explicit opt in of the configuration
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if len(cert) > 0 || len(key) > 0 {
+		if len(cert) == 0 || len(key) == 0 {
+			return errors.New("a certificate and its key must be set together")
+		}
+
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return err
+		}
+
+		config.Certificates = []tls.Certificate{pair}
+	}
+
+	if len(ca) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return errors.New("CA: no PEM encoded certificates found")
+		}
+
+		config.RootCAs, config.ClientCAs = pool, pool
+	}
+
+	c.Config = config
+	return nil
+}
+
+// pemData returns the inline PEM data or the content of the file at path
+func pemData(inline, path string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+
+	if path == "" {
+		return nil, nil
+	}
+
+	return os.ReadFile(filepath.Clean(path))
+}
@@ -0,0 +1,128 @@
+package envs_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+// selfSigned returns a PEM encoded self signed certificate and its PKCS #8 private key
+func selfSigned(t *testing.T) (certPEM, keyPEM string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "envs.test"},
+		DNSNames:              []string{"envs.test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+
+	return certPEM, keyPEM
+}
+
+func TestTLSConfig(t *testing.T) {
+	type Config struct {
+		TLS    envs.TLSConfig  `env:"TLS"`
+		Client *envs.TLSConfig `env:"CLIENT"`
+	}
+
+	cert, key := selfSigned(t)
+	dir := t.TempDir()
+	for name, data := range map[string]string{"cert.pem": cert, "key.pem": key} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("files", func(t *testing.T) {
+		values := envs.FromMap(map[string]string{
+			"APP_TLS_CERT_FILE":   filepath.Join(dir, "cert.pem"),
+			"APP_TLS_KEY_FILE":    filepath.Join(dir, "key.pem"),
+			"APP_TLS_CA_FILE":     filepath.Join(dir, "cert.pem"),
+			"APP_TLS_SERVER_NAME": "envs.test",
+		})
+
+		var cfg Config
+		if err := envs.NewParserOpts(envs.WithValueFunc(values)).ParseStruct(&cfg, "APP"); err != nil {
+			t.Fatalf("ParseStruct() error = %v", err)
+		}
+
+		got := cfg.TLS.Config
+		if got == nil || len(got.Certificates) != 1 || got.RootCAs == nil || got.ServerName != "envs.test" {
+			t.Fatalf("Config = %+v, want a certificate, root CAs and a server name", got)
+		}
+
+		if cfg.Client != nil {
+			t.Errorf("Client = %+v, want nil without any value", cfg.Client)
+		}
+	})
+
+	t.Run("inline", func(t *testing.T) {
+		values := envs.FromMap(map[string]string{
+			"APP_CLIENT_CERT": strings.ReplaceAll(cert, "\n", `\n`),
+			"APP_CLIENT_KEY":  strings.ReplaceAll(key, "\n", `\n`),
+		})
+
+		var cfg Config
+		if err := envs.NewParserOpts(envs.WithValueFunc(values)).ParseStruct(&cfg, "APP"); err != nil {
+			t.Fatalf("ParseStruct() error = %v", err)
+		}
+
+		if cfg.Client == nil || cfg.Client.Config == nil || len(cfg.Client.Config.Certificates) != 1 {
+			t.Fatalf("Client = %+v, want a certificate", cfg.Client)
+		}
+
+		if cfg.TLS.Config != nil {
+			t.Errorf("TLS.Config = %+v, want nil without any value", cfg.TLS.Config)
+		}
+	})
+
+	tests := map[string]map[string]string{
+		"missing key":  {"APP_TLS_CERT": cert},
+		"missing file": {"APP_TLS_CA_FILE": filepath.Join(dir, "missing.pem")},
+		"bad CA":       {"APP_TLS_CA": "not a certificate"},
+	}
+
+	for name, values := range tests {
+		t.Run(name, func(t *testing.T) {
+			var cfg Config
+			err := envs.NewParserOpts(envs.WithValueFunc(envs.FromMap(values))).ParseStruct(&cfg, "APP")
+			if err == nil || !strings.Contains(err.Error(), "APP_TLS") {
+				t.Errorf("ParseStruct() error = %v, want an error naming APP_TLS", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package envs
+
+import (
+	"fmt"
+	"os"
+	r "reflect"
+	"sort"
+)
+
+// ToMap is the inverse of ParseStruct, it returns the value of every field of cfg under the exact key the parser
+// reads it from, ready for exec.Cmd.Env, test fixtures or templates. zero values are kept as such, so parsing the
+// result gives cfg back instead of falling back to tag defaults, and Redacted values are revealed
+func ToMap(cfg interface{}, prefix string) (map[string]string, error) {
+	return NewParser(nil, nil).ToMap(cfg, prefix)
+}
+
+// ToMap returns the value of every field of cfg under the key the parser reads it from,
+// an empty prefix falls back to the one configured with WithPrefix
+func (m *Parser) ToMap(cfg interface{}, prefix string) (map[string]string, error) {
+	if prefix == "" {
+		prefix = m.prefix
+	}
+
+	values := map[string]string{}
+	err := m.walk(r.ValueOf(cfg), prefix, "", func(f field) error {
+		value := f.Value
+		if rev, ok := value.Interface().(revealer); ok {
+			value = r.ValueOf(rev.reveal())
+		}
+
+		values[f.Key] = m.formatField(value, f.Tag)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// Apply sets a process environment variable for every field of cfg with the keys and values ToMap returns,
+// for bootstrapping child tooling or building a full environment in tests from a typed value
+func Apply(cfg interface{}, prefix string) error {
+	return NewParser(nil, nil).Apply(cfg, prefix)
+}
+
+// Apply sets a process environment variable for every field of cfg using the parser prefix and key function
+func (m *Parser) Apply(cfg interface{}, prefix string) error {
+	values, err := m.ToMap(cfg, prefix)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := os.Setenv(key, values[key]); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// CommandEnv returns environ with the keys and values ToMap returns for cfg set in it, ready for exec.Cmd.Env so
+// supervisors can pass typed configuration to subprocesses. pass os.Environ() to inherit the process environment
+// or nil for a standalone environment, the result is sorted
+func CommandEnv(cfg interface{}, prefix string, environ []string) ([]string, error) {
+	return NewParser(nil, nil).CommandEnv(cfg, prefix, environ)
+}
+
+// CommandEnv returns environ with the fields of cfg set in it using the parser prefix and key function
+func (m *Parser) CommandEnv(cfg interface{}, prefix string, environ []string) ([]string, error) {
+	values, err := m.ToMap(cfg, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]string, len(environ)+len(values))
+	for _, kv := range environ {
+		k, v := cutEnv(kv)
+		merged[k] = v
+	}
+
+	for k, v := range values {
+		merged[k] = v
+	}
+
+	env := make([]string, 0, len(merged))
+	for k, v := range merged {
+		env = append(env, k+"="+v)
+	}
+
+	sort.Strings(env)
+	return env, nil
+}
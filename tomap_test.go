@@ -0,0 +1,143 @@
+package envs_test
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+func TestToMap(t *testing.T) {
+	type Server struct {
+		Port    int           `env:"PORT,8080"`
+		Timeout time.Duration `env:"TIMEOUT"`
+	}
+
+	type Config struct {
+		Name   string                `env:"NAME,default=svc"`
+		Server *Server               `env:"SERVER"`
+		Token  envs.Redacted[string] `env:"TOKEN"`
+		Tags   map[string]string     `env:"TAGS"`
+		Groups map[string][]string   `env:"GROUPS"`
+		Debug  bool                  `env:"DEBUG"`
+	}
+
+	cfg := Config{
+		Server: &Server{Port: 0, Timeout: time.Minute},
+		Token:  envs.NewRedacted("s3cret"),
+		Tags:   map[string]string{"team": "core"},
+		Groups: map[string][]string{"a": {"x", "y"}, "b": {"z"}},
+		Debug:  true,
+	}
+
+	got, err := envs.ToMap(cfg, "APP")
+	if err != nil {
+		t.Fatalf("ToMap() error = %v", err)
+	}
+
+	want := map[string]string{
+		"APP_NAME":           "",
+		"APP_SERVER_PORT":    "0",
+		"APP_SERVER_TIMEOUT": "1m0s",
+		"APP_TOKEN":          "s3cret",
+		"APP_TAGS":           "team:core",
+		"APP_GROUPS":         "a:x|y,b:z",
+		"APP_DEBUG":          "true",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ToMap() = %v, want %v", got, want)
+	}
+
+	var parsed Config
+	if err := envs.NewParserOpts(envs.WithLookupFunc(func(key, _ string) (string, bool, error) {
+		val, ok := got[key]
+		return val, ok, nil
+	})).ParseStruct(&parsed, "APP"); err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(parsed, cfg) {
+		t.Errorf("ParseStruct(ToMap()) = %+v, want %+v", parsed, cfg)
+	}
+
+	if _, err := envs.ToMap(42, ""); err == nil {
+		t.Error("ToMap() expected an error for a non struct value")
+	}
+}
+
+func TestApply(t *testing.T) {
+	type Config struct {
+		Name  string   `env:"NAME,default=svc"`
+		Port  int      `env:"PORT"`
+		Hosts []string `env:"HOSTS"`
+	}
+
+	for _, key := range []string{"APPLY_NAME", "APPLY_PORT", "APPLY_HOSTS"} {
+		t.Setenv(key, "before")
+	}
+
+	cfg := Config{Port: 8080, Hosts: []string{"a", "b"}}
+	if err := envs.Apply(cfg, "APPLY"); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	want := map[string]string{"APPLY_NAME": "", "APPLY_PORT": "8080", "APPLY_HOSTS": "a,b"}
+	for key, val := range want {
+		if got, ok := os.LookupEnv(key); !ok || got != val {
+			t.Errorf("%s = %q, %v, want %q", key, got, ok, val)
+		}
+	}
+
+	var parsed Config
+	if err := envs.NewParser(nil, nil).ParseStruct(&parsed, "APPLY"); err != nil || !reflect.DeepEqual(parsed, cfg) {
+		t.Errorf("ParseStruct() = %+v, %v, want %+v", parsed, err, cfg)
+	}
+}
+
+func TestCommandEnv(t *testing.T) {
+	type Config struct {
+		Port  int    `env:"PORT"`
+		Level string `env:"LEVEL"`
+	}
+
+	cfg := Config{Port: 8080, Level: "debug"}
+	got, err := envs.CommandEnv(cfg, "CHILD", []string{"PATH=/bin", "CHILD_PORT=1", "HOME=/root"})
+	if err != nil {
+		t.Fatalf("CommandEnv() error = %v", err)
+	}
+
+	want := []string{"CHILD_LEVEL=debug", "CHILD_PORT=8080", "HOME=/root", "PATH=/bin"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CommandEnv() = %v, want %v", got, want)
+	}
+
+	if got, err = envs.CommandEnv(cfg, "CHILD", nil); err != nil || len(got) != 2 {
+		t.Errorf("CommandEnv() standalone = %v, %v, want only the config", got, err)
+	}
+
+	// windows keeps the directory of every drive in a variable named like =C:
+	got, err = envs.CommandEnv(cfg, "CHILD", []string{`=C:=C:\app`, `=D:=D:\data`})
+	want = []string{`=C:=C:\app`, `=D:=D:\data`, "CHILD_LEVEL=debug", "CHILD_PORT=8080"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("CommandEnv() = %v, %v, want %v", got, err, want)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	cmd := exec.Command("sh", "-c", "echo $CHILD_PORT")
+	if cmd.Env, err = envs.CommandEnv(cfg, "CHILD", os.Environ()); err != nil {
+		t.Fatalf("CommandEnv() error = %v", err)
+	}
+
+	if out, err := cmd.Output(); err != nil || strings.TrimSpace(string(out)) != "8080" {
+		t.Errorf("child saw CHILD_PORT = %q, %v, want 8080", out, err)
+	}
+}
@@ -0,0 +1,87 @@
+package envs
+
+import (
+	"fmt"
+	r "reflect"
+)
+
+// field is a single value the parser would read, found by walking a struct the same way ParseStruct does
+type field struct {
+	// Path is the dotted path of the field in the struct, like Server.Port
+	Path string
+	// Key is the built key, like APP_SERVER_PORT
+	Key   string
+	Type  r.Type
+	Value r.Value
+	Tag   tagOptions
+}
+
+// walk calls fn for every leaf field of v, nested structs are walked with their key as prefix
+func (m *Parser) walk(v r.Value, prefix, path string, fn func(f field) error) error {
+	return m.walkStruct(v, prefix, prefix, path, map[r.Type]bool{}, fn)
+}
+
+// walkStruct is walk keeping the struct types being walked in visiting to stop at recursive types,
+// root is the prefix walking started with
+func (m *Parser) walkStruct(
+	v r.Value, root, prefix, path string, visiting map[r.Type]bool, fn func(f field) error,
+) error {
+	for v.Kind() == r.Pointer {
+		if v.IsNil() {
+			v = r.New(v.Type().Elem())
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != r.Struct {
+		return fmt.Errorf("destination is of type %s and not struct", v.Kind())
+	}
+
+	if visiting[v.Type()] {
+		return fmt.Errorf("%s: %w %s", path, ErrCycle, v.Type())
+	}
+
+	visiting[v.Type()] = true
+	defer delete(visiting, v.Type())
+
+	for _, sf := range m.structFields(v.Type()) {
+		fieldPath := joinKey(path, sf.name)
+		key := m.fieldKey(sf, root, prefix, fieldPath)
+
+		if isNested(sf.typ) {
+			if err := m.walkStruct(v.Field(sf.index), root, key, fieldPath, visiting, fn); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err := fn(field{Path: fieldPath, Key: m.BuildKey(key), Type: sf.typ, Value: v.Field(sf.index), Tag: sf.opts})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isNested reports whether the parser descends into values of t instead of reading them from a single key,
+// pointers to such types are nested as well
+func isNested(t r.Type) bool {
+	for t.Kind() == r.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != r.Struct || t == timeType || t == urlType.Elem() {
+		return false
+	}
+
+	// keys and certificate pools are read from a single PEM value
+	ptr := r.PointerTo(t)
+	if isKeyType(ptr) || ptr == certPoolType {
+		return false
+	}
+
+	return !ptr.Implements(textUnmarshalerType)
+}
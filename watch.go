@@ -0,0 +1,139 @@
+package envs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Waiter is implemented by sources that can block until their values change, like ConsulSource.
+// a running Watcher reloads whenever Wait returns without an error
+type Waiter interface {
+	Wait(ctx context.Context) error
+}
+
+// waitFor waits for a change of source when it is a Waiter, sources that never report changes wait for ctx
+// to be done, so wrappers like Cached and WithRetry can forward Wait to any source
+func waitFor(ctx context.Context, source Source) error {
+	if waiter, ok := source.(Waiter); ok {
+		return waiter.Wait(ctx)
+	}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// Watcher parses T again every interval, when its source reports a change or when triggered, and calls the
+// subscribers of its Holder with the old and new configuration whenever the result differs from the previous one.
+// it is the foundation for changing the configuration without restarting. a Watcher is safe for concurrent use
+type Watcher[T any] struct {
+	*Holder[T]
+
+	interval time.Duration
+	trigger  chan struct{}
+
+	mu      sync.Mutex
+	onError []func(err error)
+}
+
+// NewWatcher parses T with a parser built from opts and returns a Watcher holding the result, interval is how
+// often Run parses again and zero only reloads on source changes and triggers
+func NewWatcher[T any](prefix string, interval time.Duration, opts ...Option) (*Watcher[T], error) {
+	h, err := NewHolder[T](prefix, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watcher[T]{Holder: h, interval: interval, trigger: make(chan struct{}, 1)}, nil
+}
+
+// Current returns the last successfully parsed configuration, it is the same as Load
+func (w *Watcher[T]) Current() T {
+	return w.Load()
+}
+
+// OnChange registers fn to be called with the old and new configuration after a reload changed it,
+// it is the same as Subscribe
+func (w *Watcher[T]) OnChange(fn func(old, new T)) {
+	w.Subscribe(fn)
+}
+
+// OnError registers fn to be called with the errors of reloads done by Run, the current configuration is kept
+func (w *Watcher[T]) OnError(fn func(err error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.onError = append(w.onError, fn)
+}
+
+// Trigger asks a running Watcher to reload, triggers arriving while one is pending are merged
+func (w *Watcher[T]) Trigger() {
+	select {
+	case w.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Run reloads every interval, on triggers and on changes reported by sources implementing Waiter until ctx is
+// done and returns its error, reload errors are passed to the OnError callbacks
+func (w *Watcher[T]) Run(ctx context.Context) error {
+	var tick <-chan time.Time
+	if w.interval > 0 {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	if waiter, ok := w.compiled.parser.source.(Waiter); ok {
+		go w.wait(ctx, waiter)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick:
+		case <-w.trigger:
+		}
+
+		if _, err := w.Reload(ctx); err != nil && ctx.Err() == nil {
+			w.reportError(err)
+		}
+	}
+}
+
+// wait triggers a reload every time waiter returns, failed waits are reported and retried after a second
+func (w *Watcher[T]) wait(ctx context.Context, waiter Waiter) {
+	for ctx.Err() == nil {
+		err := waiter.Wait(ctx)
+		if err == nil {
+			w.Trigger()
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		w.reportError(err)
+
+		timer := time.NewTimer(time.Second)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (w *Watcher[T]) reportError(err error) {
+	w.mu.Lock()
+	callbacks := w.onError
+	w.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(err)
+	}
+}
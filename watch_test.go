@@ -0,0 +1,114 @@
+package envs_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OZahed/envs"
+)
+
+// notifyingSource is a map backed source implementing envs.Waiter
+type notifyingSource struct {
+	mu      sync.Mutex
+	values  map[string]string
+	changes chan struct{}
+}
+
+func (s *notifyingSource) Lookup(_ context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	val, ok := s.values[key]
+	if key == "WATCH_PORT" && val == "broken" {
+		return "", false, errors.New("store unavailable")
+	}
+
+	return val, ok, nil
+}
+
+func (s *notifyingSource) Wait(ctx context.Context) error {
+	select {
+	case <-s.changes:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *notifyingSource) set(key, val string) {
+	s.mu.Lock()
+	s.values[key] = val
+	s.mu.Unlock()
+}
+
+func TestWatcher(t *testing.T) {
+	type Config struct {
+		Port  int    `env:"PORT"`
+		Level string `env:"LEVEL,default=info"`
+	}
+
+	source := &notifyingSource{values: map[string]string{"WATCH_PORT": "8080"}, changes: make(chan struct{})}
+	w, err := envs.NewWatcher[Config]("WATCH", 0, envs.WithSource(source))
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	if got, want := w.Current(), (Config{Port: 8080, Level: "info"}); got != want {
+		t.Errorf("Current() = %+v, want %+v", got, want)
+	}
+
+	type change struct{ old, new Config }
+	changes := make(chan change, 10)
+	errs := make(chan error, 10)
+	w.OnChange(func(old, new Config) { changes <- change{old, new} })
+	w.OnError(func(err error) { errs <- err })
+
+	if changed, err := w.Reload(context.Background()); changed || err != nil {
+		t.Errorf("Reload() = %v, %v without changes", changed, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	source.set("WATCH_LEVEL", "debug")
+	source.changes <- struct{}{}
+
+	select {
+	case c := <-changes:
+		if want := (change{Config{8080, "info"}, Config{8080, "debug"}}); c != want {
+			t.Errorf("OnChange() got %+v, want %+v", c, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no change reported after the source changed")
+	}
+
+	source.set("WATCH_PORT", "broken")
+	w.Trigger()
+
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("OnError() got a nil error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no error reported for a failed reload")
+	}
+
+	if got, want := w.Current(), (Config{Port: 8080, Level: "debug"}); got != want {
+		t.Errorf("Current() after a failed reload = %+v, want %+v", got, want)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+
+	source.set("WATCH_PORT", "x")
+	if _, err := envs.NewWatcher[Config]("WATCH", 0, envs.WithSource(source)); err == nil {
+		t.Error("NewWatcher() expected an error for an invalid value")
+	}
+}